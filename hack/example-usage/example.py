@@ -3,11 +3,23 @@
 MCP Client to connect to a server via HTTP and list available tools.
 """
 import asyncio
+import base64
 import httpx
 import json
 from fastmcp import Client
 from fastmcp.client.transports import StreamableHttpTransport
 
+def get_kubeconfig_auth_object(kubeconfig_file, context=None):
+    with open(kubeconfig_file, "rb") as f:
+      kubeconfig = f.read()
+
+    headers = {
+      "x-target-k8s-kubeconfig": base64.b64encode(kubeconfig).decode("utf-8")
+    }
+    if context:
+      headers["x-target-k8s-context"] = context
+    return headers
+
 def get_auth_object(cluster_json_file, authType):
     # authType: "token" or "cert"
 
@@ -33,15 +45,16 @@ async def connect_and_list_pods():
     server_url = "http://localhost:8085/mcp"
     cluster_json_file = "test-cluster.json"
     authType = "token"
-    base64_enabled = False
+    # send the auth object as a JSON envelope in the Authorization header instead of discrete headers
+    envelope_enabled = False
     
     auth_object = get_auth_object(cluster_json_file, authType)
     
     custom_headers = {}
-    if base64_enabled:
+    if envelope_enabled:
        custom_headers["Authorization"] = json.dumps(auth_object)
     else:
-       custom_headers["Authorization"] = json.dumps(auth_object)
+       custom_headers = auth_object
 
     try:
         # Define the tool parameters
@@ -0,0 +1,85 @@
+#!/usr/bin/env python3
+"""
+Local stand-in OIDC server to try the auth-headers token exchange (RFC 8693).
+
+It serves the OIDC discovery document and a token endpoint that exchanges any subject token
+for the cluster token given in the CLUSTER_TOKEN environment variable.
+
+Usage:
+  CLUSTER_TOKEN=$(kubectl create token my-sa) python3 oidc_stand_in.py --port 9000
+
+and in config.toml:
+  [cluster_provider_configs.auth-headers.token_exchange]
+  issuer_url = "http://localhost:9000"
+  client_id = "ext-kyma-mcp"
+  audience = "kubernetes"
+"""
+import argparse
+import json
+import os
+from http.server import BaseHTTPRequestHandler, HTTPServer
+from urllib.parse import parse_qs
+
+GRANT_TYPE_TOKEN_EXCHANGE = "urn:ietf:params:oauth:grant-type:token-exchange"
+
+
+class Handler(BaseHTTPRequestHandler):
+    issuer = ""
+    expires_in = 300
+
+    def _send_json(self, status, body):
+        payload = json.dumps(body).encode("utf-8")
+        self.send_response(status)
+        self.send_header("Content-Type", "application/json")
+        self.send_header("Content-Length", str(len(payload)))
+        self.end_headers()
+        self.wfile.write(payload)
+
+    def do_GET(self):
+        if self.path != "/.well-known/openid-configuration":
+            self._send_json(404, {"error": "not_found"})
+            return
+        self._send_json(200, {
+            "issuer": self.issuer,
+            "token_endpoint": self.issuer + "/token",
+            "grant_types_supported": [GRANT_TYPE_TOKEN_EXCHANGE],
+        })
+
+    def do_POST(self):
+        if self.path != "/token":
+            self._send_json(404, {"error": "not_found"})
+            return
+        length = int(self.headers.get("Content-Length", 0))
+        form = parse_qs(self.rfile.read(length).decode("utf-8"))
+        if form.get("grant_type", [""])[0] != GRANT_TYPE_TOKEN_EXCHANGE:
+            self._send_json(400, {"error": "unsupported_grant_type"})
+            return
+        if not form.get("subject_token", [""])[0]:
+            self._send_json(400, {"error": "invalid_request", "error_description": "subject_token is required"})
+            return
+        print(f"exchanging token for audience={form.get('audience', [''])[0]} client_id={form.get('client_id', [''])[0]}")
+        self._send_json(200, {
+            "access_token": os.environ["CLUSTER_TOKEN"],
+            "issued_token_type": "urn:ietf:params:oauth:token-type:access_token",
+            "token_type": "Bearer",
+            "expires_in": self.expires_in,
+        })
+
+
+def main():
+    parser = argparse.ArgumentParser(description=__doc__, formatter_class=argparse.RawDescriptionHelpFormatter)
+    parser.add_argument("--port", type=int, default=9000)
+    parser.add_argument("--expires-in", type=int, default=300)
+    args = parser.parse_args()
+
+    if not os.environ.get("CLUSTER_TOKEN"):
+        parser.error("CLUSTER_TOKEN environment variable is required")
+
+    Handler.issuer = f"http://localhost:{args.port}"
+    Handler.expires_in = args.expires_in
+    print(f"stand-in OIDC issuer listening on {Handler.issuer}")
+    HTTPServer(("localhost", args.port), Handler).serve_forever()
+
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,229 @@
+// Package audit records every tool call as a JSON line, with the credentials and secrets redacted.
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const (
+	// StdoutDestination writes the audit log to the standard output.
+	StdoutDestination = "stdout"
+
+	// maxArgumentLength is the maximum length of a string argument, longer values are replaced by their length and digest.
+	maxArgumentLength = 256
+	redacted          = "[REDACTED]"
+)
+
+// sensitiveArgumentKeys are the (lowercase) argument key fragments whose values are never written.
+var sensitiveArgumentKeys = []string{
+	"token", "password", "passwd", "credential", "authorization", "apikey", "api_key", "api-key",
+	"private", "client-key", "client_key", "clientkey", "certificate-authority", "certificate_authority",
+	"kubeconfig", "x-target-k8s-",
+}
+
+// sensitiveArgumentKeySuffixes are the (lowercase) argument key suffixes whose values are never written, e.g.
+// secret or clientSecret. Keys referencing a Secret (e.g. secretName, secretNamespace) are kept for the audit.
+var sensitiveArgumentKeySuffixes = []string{
+	"secret", "secretkey", "secret_key", "secret-key", "secretaccesskey", "secret_access_key",
+}
+
+// Entry is a single audit log entry, one per tool call or per request rejected before any tool ran.
+type Entry struct {
+	Timestamp time.Time `json:"timestamp"`
+	SessionID string    `json:"sessionId,omitempty"`
+	// Tool and Toolset are empty for rejected requests, since the auth headers are checked before the tool runs.
+	Tool        string         `json:"tool,omitempty"`
+	Toolset     string         `json:"toolset,omitempty"`
+	Rejected    bool           `json:"rejected,omitempty"`
+	Destructive bool           `json:"destructive"`
+	Arguments   map[string]any `json:"arguments,omitempty"`
+	Target      string         `json:"target,omitempty"`
+	Server      string         `json:"server,omitempty"`
+	Subject     string         `json:"subject,omitempty"`
+	DurationMs  int64          `json:"durationMs"`
+	Success     bool           `json:"success"`
+	Error       string         `json:"error,omitempty"`
+}
+
+// Logger writes the audit entries as JSON lines.
+type Logger struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+	closer  io.Closer
+}
+
+// NewLogger creates a logger writing to the destination, either StdoutDestination (or "-") or a file path.
+// Files are opened in append mode and created with owner-only permissions.
+func NewLogger(destination string) (*Logger, error) {
+	switch destination {
+	case "":
+		return nil, nil
+	case StdoutDestination, "-":
+		return &Logger{encoder: json.NewEncoder(os.Stdout)}, nil
+	}
+	file, err := os.OpenFile(destination, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log %s: %w", destination, err)
+	}
+	return &Logger{encoder: json.NewEncoder(file), closer: file}, nil
+}
+
+// Record writes the entry, the arguments are sanitized first.
+func (l *Logger) Record(entry *Entry) error {
+	if l == nil {
+		return nil
+	}
+	entry.Arguments = SanitizeArguments(entry.Arguments)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.encoder.Encode(entry)
+}
+
+// Close closes the audit log file, if any.
+func (l *Logger) Close() error {
+	if l == nil || l.closer == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.closer.Close()
+}
+
+// SanitizeArguments returns a copy of the tool arguments with the sensitive values redacted and the long
+// values replaced by their length and digest. Manifests are parsed, with the data of their Secrets redacted.
+func SanitizeArguments(arguments map[string]any) map[string]any {
+	if arguments == nil {
+		return nil
+	}
+	ret := make(map[string]any, len(arguments))
+	for key, value := range arguments {
+		if isSensitiveKey(key) {
+			ret[key] = redacted
+			continue
+		}
+		ret[key] = sanitizeValue(value)
+	}
+	return ret
+}
+
+func sanitizeValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		return SanitizeArguments(redactSecretData(v))
+	case []any:
+		ret := make([]any, len(v))
+		for i, item := range v {
+			ret[i] = sanitizeValue(item)
+		}
+		return ret
+	case string:
+		if manifest, ok := parseManifest(v); ok {
+			return sanitizeValue(manifest)
+		}
+		if len(v) > maxArgumentLength || (strings.Contains(v, "kind") && strings.Contains(v, "Secret")) {
+			// the value may carry secrets (e.g. a manifest that cannot be parsed), it is never written in part
+			return fmt.Sprintf("[%d bytes, sha256:%x]", len(v), sha256.Sum256([]byte(v)))
+		}
+		return v
+	}
+	return value
+}
+
+// parseManifest parses a YAML or JSON manifest, one object or several documents, each having a kind.
+func parseManifest(value string) (any, bool) {
+	if !strings.Contains(value, "kind") {
+		return nil, false
+	}
+	decoder := yaml.NewYAMLOrJSONDecoder(strings.NewReader(value), 4096)
+	var objects []any
+	for {
+		var object map[string]any
+		if err := decoder.Decode(&object); err != nil {
+			if !errors.Is(err, io.EOF) {
+				return nil, false
+			}
+			break
+		}
+		if object == nil {
+			// empty document
+			continue
+		}
+		if _, ok := object["kind"].(string); !ok {
+			return nil, false
+		}
+		objects = append(objects, object)
+	}
+	switch len(objects) {
+	case 0:
+		return nil, false
+	case 1:
+		return objects[0], true
+	}
+	return objects, true
+}
+
+// redactSecretData returns the object with the values of the data and stringData of a Secret (or of the Secrets
+// of a List) redacted, their keys are kept.
+func redactSecretData(object map[string]any) map[string]any {
+	kind, _ := object["kind"].(string)
+	items, isList := object["items"].([]any)
+	if kind != "Secret" && !isList {
+		return object
+	}
+	ret := make(map[string]any, len(object))
+	for key, value := range object {
+		ret[key] = value
+	}
+	if isList {
+		redactedItems := make([]any, len(items))
+		for i, item := range items {
+			if itemMap, ok := item.(map[string]any); ok {
+				item = redactSecretData(itemMap)
+			}
+			redactedItems[i] = item
+		}
+		ret["items"] = redactedItems
+	}
+	if kind == "Secret" {
+		for _, field := range []string{"data", "stringData"} {
+			data, ok := object[field].(map[string]any)
+			if !ok {
+				if object[field] != nil {
+					ret[field] = redacted
+				}
+				continue
+			}
+			redactedData := make(map[string]any, len(data))
+			for key := range data {
+				redactedData[key] = redacted
+			}
+			ret[field] = redactedData
+		}
+	}
+	return ret
+}
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, fragment := range sensitiveArgumentKeys {
+		if strings.Contains(key, fragment) {
+			return true
+		}
+	}
+	for _, suffix := range sensitiveArgumentKeySuffixes {
+		if strings.HasSuffix(key, suffix) {
+			return true
+		}
+	}
+	return false
+}
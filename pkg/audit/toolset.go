@@ -0,0 +1,111 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/containers/kubernetes-mcp-server/pkg/api"
+	kmskubernetes "github.com/containers/kubernetes-mcp-server/pkg/kubernetes"
+	"github.com/containers/kubernetes-mcp-server/pkg/mcplog"
+	"github.com/containers/kubernetes-mcp-server/pkg/toolsets"
+	"github.com/mfaizanse/ext-kyma-mcp/pkg/kubernetes"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+)
+
+// Toolset decorates a toolset so that every call of its tools is recorded in the audit log.
+type Toolset struct {
+	api.Toolset
+	logger   *Logger
+	provider kmskubernetes.Provider
+}
+
+var _ api.Toolset = (*Toolset)(nil)
+
+// WrapToolsets replaces the registered toolsets with audited ones. It must be called before the MCP server
+// is created, since the server resolves the configured toolsets from the registry.
+// The requests rejected by the provider before any tool runs (e.g. by the server policy) are recorded too.
+func WrapToolsets(logger *Logger, provider kmskubernetes.Provider) {
+	if logger == nil {
+		return
+	}
+	if reporter, ok := provider.(kubernetes.RejectionReporter); ok {
+		reporter.SetRejectionHandler(func(ctx context.Context, rejection *kubernetes.Rejection) {
+			recordRejection(ctx, logger, rejection)
+		})
+	}
+	registered := toolsets.Toolsets()
+	toolsets.Clear()
+	for _, toolset := range registered {
+		toolsets.Register(&Toolset{Toolset: toolset, logger: logger, provider: provider})
+	}
+}
+
+func (t *Toolset) GetTools(o api.Openshift) []api.ServerTool {
+	tools := t.Toolset.GetTools(o)
+	ret := make([]api.ServerTool, len(tools))
+	for i, tool := range tools {
+		tool.Handler = t.auditHandler(tool.Tool, tool.Handler)
+		ret[i] = tool
+	}
+	return ret
+}
+
+func (t *Toolset) auditHandler(tool api.Tool, handler api.ToolHandlerFunc) api.ToolHandlerFunc {
+	// destructive tools are flagged like the server flags them for disable_destructive
+	destructive := !ptr.Deref(tool.Annotations.ReadOnlyHint, false) && ptr.Deref(tool.Annotations.DestructiveHint, false)
+	return func(params api.ToolHandlerParams) (*api.ToolCallResult, error) {
+		start := time.Now()
+		result, err := handler(params)
+
+		entry := &Entry{
+			Timestamp:   start.UTC(),
+			Tool:        tool.Name,
+			Toolset:     t.GetName(),
+			Destructive: destructive,
+			Arguments:   params.GetArguments(),
+			DurationMs:  time.Since(start).Milliseconds(),
+			Success:     err == nil && (result == nil || result.Error == nil),
+		}
+		entry.SessionID = sessionID(params.Context)
+		if target, ok := entry.Arguments[t.provider.GetTargetParameterName()].(string); ok {
+			entry.Target = target
+		}
+		if resolver, ok := t.provider.(kubernetes.AuditInfoResolver); ok {
+			entry.Server, entry.Subject = resolver.AuditInfo(params.Context, entry.Target)
+		}
+		switch {
+		case err != nil:
+			entry.Error = err.Error()
+		case result != nil && result.Error != nil:
+			entry.Error = result.Error.Error()
+		}
+
+		if recordErr := t.logger.Record(entry); recordErr != nil {
+			klog.Errorf("failed to record the audit log entry of tool %s: %v", tool.Name, recordErr)
+		}
+		return result, err
+	}
+}
+
+func recordRejection(ctx context.Context, logger *Logger, rejection *kubernetes.Rejection) {
+	entry := &Entry{
+		Timestamp: time.Now().UTC(),
+		SessionID: sessionID(ctx),
+		Rejected:  true,
+		Target:    rejection.Target,
+		Server:    rejection.Server,
+		Error:     rejection.Reason,
+	}
+	if err := logger.Record(entry); err != nil {
+		klog.Errorf("failed to record the audit log entry of a rejected request: %v", err)
+	}
+}
+
+func sessionID(ctx context.Context) string {
+	if session, ok := ctx.Value(mcplog.MCPSessionContextKey).(*mcp.ServerSession); ok && session != nil {
+		return session.ID()
+	}
+	return ""
+}
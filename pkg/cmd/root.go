@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"flag"
 	"fmt"
@@ -16,7 +18,10 @@ import (
 	"time"
 
 	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/mfaizanse/ext-kyma-mcp/pkg/audit"
 	"github.com/mfaizanse/ext-kyma-mcp/pkg/config"
+	exthttp "github.com/mfaizanse/ext-kyma-mcp/pkg/http"
+	extkubernetes "github.com/mfaizanse/ext-kyma-mcp/pkg/kubernetes"
 	"github.com/spf13/cobra"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 	"k8s.io/klog/v2"
@@ -25,14 +30,18 @@ import (
 	"k8s.io/kubectl/pkg/util/i18n"
 	"k8s.io/kubectl/pkg/util/templates"
 
-	_ "github.com/mfaizanse/ext-kyma-mcp/pkg/kubernetes"
+	_ "github.com/mfaizanse/ext-kyma-mcp/pkg/toolsets/apigateway"
+	_ "github.com/mfaizanse/ext-kyma-mcp/pkg/toolsets/btp"
+	_ "github.com/mfaizanse/ext-kyma-mcp/pkg/toolsets/eventing"
+	_ "github.com/mfaizanse/ext-kyma-mcp/pkg/toolsets/istio"
 	_ "github.com/mfaizanse/ext-kyma-mcp/pkg/toolsets/kyma"
 	_ "github.com/mfaizanse/ext-kyma-mcp/pkg/toolsets/overview"
+	_ "github.com/mfaizanse/ext-kyma-mcp/pkg/toolsets/serverless"
+	_ "github.com/mfaizanse/ext-kyma-mcp/pkg/toolsets/telemetry"
 
 	// Import packages from the kubernetes-mcp-server module
 	"github.com/containers/kubernetes-mcp-server/pkg/api"
 	kmsconfig "github.com/containers/kubernetes-mcp-server/pkg/config"
-	internalhttp "github.com/containers/kubernetes-mcp-server/pkg/http"
 	"github.com/containers/kubernetes-mcp-server/pkg/kubernetes"
 	"github.com/containers/kubernetes-mcp-server/pkg/mcp"
 	"github.com/containers/kubernetes-mcp-server/pkg/output"
@@ -90,6 +99,7 @@ const (
 	flagCertificateAuthority = "certificate-authority"
 	flagDisableMultiCluster  = "disable-multi-cluster"
 	flagClusterProvider      = "cluster-provider"
+	flagAuditLog             = "audit-log"
 )
 
 // ExtendedMCPServerOptions inspires from the original MCPServerOptions to extend functionality
@@ -111,6 +121,7 @@ type ExtendedMCPServerOptions struct {
 	ServerURL            string
 	DisableMultiCluster  bool
 	ClusterProvider      string
+	AuditLog             string
 
 	ConfigPath   string
 	ConfigDir    string
@@ -174,6 +185,7 @@ func NewExtendedMCPServer(streams genericiooptions.IOStreams) *cobra.Command {
 	_ = cmd.Flags().MarkHidden(flagCertificateAuthority)
 	cmd.Flags().BoolVar(&o.DisableMultiCluster, flagDisableMultiCluster, o.DisableMultiCluster, "Disable multi cluster tools. Optional. If true, all tools will be run against the default cluster/context.")
 	cmd.Flags().StringVar(&o.ClusterProvider, flagClusterProvider, o.ClusterProvider, "Cluster provider strategy to use (one of: kubeconfig, in-cluster, kcp, disabled). If not set, the server will auto-detect based on the environment.")
+	cmd.Flags().StringVar(&o.AuditLog, flagAuditLog, o.AuditLog, "Write an audit log entry (JSON line) for every tool call to the specified file, or to stdout if set to \"stdout\". Optional. Disabled if not set.")
 
 	return cmd
 }
@@ -197,6 +209,7 @@ func (e *ExtendedMCPServerOptions) Run() error {
 	klog.V(1).Infof(" - Disable destructive tools: %t", e.StaticConfig.DisableDestructive)
 	klog.V(1).Infof(" - Stateless mode: %t", e.StaticConfig.Stateless)
 	klog.V(1).Infof(" - Telemetry enabled: %t", e.StaticConfig.Telemetry.IsEnabled())
+	klog.V(1).Infof(" - Audit log: %s", e.AuditLog)
 
 	if e.StaticConfig.ClusterProviderStrategy == "" {
 		klog.Warningf("ClusterProviderStrategy must be set explicitly in your Config to avoid unexpected behavior in Kyma environments")
@@ -214,12 +227,49 @@ func (e *ExtendedMCPServerOptions) Run() error {
 
 	var oidcProvider *oidc.Provider
 	var httpClient *http.Client
+	if e.StaticConfig.AuthorizationURL != "" {
+		ctx := context.Background()
+		if e.StaticConfig.CertificateAuthority != "" {
+			caCert, err := os.ReadFile(e.StaticConfig.CertificateAuthority)
+			if err != nil {
+				return fmt.Errorf("failed to read CA certificate from %s: %w", e.StaticConfig.CertificateAuthority, err)
+			}
+			caCertPool := x509.NewCertPool()
+			if !caCertPool.AppendCertsFromPEM(caCert) {
+				return fmt.Errorf("failed to append CA certificate from %s to pool", e.StaticConfig.CertificateAuthority)
+			}
+			transport := http.DefaultTransport.(*http.Transport).Clone()
+			transport.TLSClientConfig = &tls.Config{RootCAs: caCertPool}
+			httpClient = &http.Client{Transport: transport}
+			ctx = oidc.ClientContext(ctx, httpClient)
+		}
+		var err error
+		if oidcProvider, err = oidc.NewProvider(ctx, e.StaticConfig.AuthorizationURL); err != nil {
+			return fmt.Errorf("unable to setup OIDC provider: %w", err)
+		}
+	}
+
+	// Token exchange wraps the provider, so it is not enabled for the auth-headers provider that exchanges
+	// the tokens itself (token_exchange provider config) and whose capabilities (e.g. token verification)
+	// must remain accessible.
+	var providerOptions []kubernetes.ProviderOption
+	if oidcProvider != nil && strategy != config.ClusterProviderAuthHeaders {
+		providerOptions = append(providerOptions, kubernetes.WithTokenExchange(oidcProvider, httpClient))
+	}
 
-	provider, err := kubernetes.NewProvider(e.StaticConfig, kubernetes.WithTokenExchange(oidcProvider, httpClient))
+	provider, err := kubernetes.NewProvider(e.StaticConfig, providerOptions...)
 	if err != nil {
 		return fmt.Errorf("unable to create kubernetes target provider: %w", err)
 	}
 
+	auditLogger, err := audit.NewLogger(e.AuditLog)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = auditLogger.Close() }()
+	// The toolsets are wrapped before the MCP server resolves them from the registry
+	audit.WrapToolsets(auditLogger, provider)
+
 	mcpServer, err := mcp.NewServer(mcp.Configuration{
 		StaticConfig: e.StaticConfig,
 	}, provider)
@@ -238,12 +288,12 @@ func (e *ExtendedMCPServerOptions) Run() error {
 
 	// Set up SIGHUP handler for configuration reload
 	if e.ConfigPath != "" || e.ConfigDir != "" {
-		e.setupSIGHUPHandler(mcpServer)
+		e.setupSIGHUPHandler(mcpServer, provider)
 	}
 
 	if e.StaticConfig.Port != "" {
 		ctx := context.Background()
-		return internalhttp.Serve(ctx, mcpServer, e.StaticConfig, oidcProvider, httpClient)
+		return exthttp.Serve(ctx, mcpServer, e.StaticConfig, provider, oidcProvider, httpClient)
 	}
 
 	ctx := context.Background()
@@ -361,6 +411,10 @@ func (m *ExtendedMCPServerOptions) Validate() error {
 	if !m.StaticConfig.RequireOAuth && (m.StaticConfig.OAuthAudience != "" || m.StaticConfig.AuthorizationURL != "" || m.StaticConfig.ServerURL != "" || m.StaticConfig.CertificateAuthority != "") {
 		return fmt.Errorf("oauth-audience, authorization-url, server-url and certificate-authority are only valid if require-oauth is enabled. Missing --port may implicitly set require-oauth to false")
 	}
+	if m.StaticConfig.ClusterProviderStrategy == config.ClusterProviderAuthHeaders && m.StaticConfig.OAuthAudience != "" &&
+		config.GetAuthHeadersProviderConfig(m.StaticConfig).GetTokenReviewMode() == config.TokenReviewModeSelfSubjectReview {
+		return fmt.Errorf("oauth-audience cannot be validated with token_review_mode %s, use %s", config.TokenReviewModeSelfSubjectReview, config.TokenReviewModeTokenReview)
+	}
 	if m.StaticConfig.AuthorizationURL != "" {
 		u, err := url.Parse(m.StaticConfig.AuthorizationURL)
 		if err != nil {
@@ -373,6 +427,9 @@ func (m *ExtendedMCPServerOptions) Validate() error {
 			klog.Warningf("authorization-url is using http://, this is not recommended production use")
 		}
 	}
+	if (m.AuditLog == audit.StdoutDestination || m.AuditLog == "-") && m.StaticConfig.Port == "" {
+		return fmt.Errorf("audit-log cannot be written to stdout with the STDIO transport, use a file instead")
+	}
 	// Validate that certificate_authority is a valid file
 	if caValue := strings.TrimSpace(m.StaticConfig.CertificateAuthority); caValue != "" {
 		if _, err := os.Stat(caValue); err != nil {
@@ -384,7 +441,9 @@ func (m *ExtendedMCPServerOptions) Validate() error {
 
 // setupSIGHUPHandler sets up a signal handler to reload configuration on SIGHUP.
 // This is a blocking call that runs in a separate goroutine.
-func (m *ExtendedMCPServerOptions) setupSIGHUPHandler(mcpServer *mcp.Server) {
+// Providers implementing kubernetes.ConfigReloader (e.g. auth-headers) also reload their configuration,
+// which re-reads key material such as the envelope keys.
+func (m *ExtendedMCPServerOptions) setupSIGHUPHandler(mcpServer *mcp.Server, provider kubernetes.Provider) {
 	sigHupCh := make(chan os.Signal, 1)
 	signal.Notify(sigHupCh, syscall.SIGHUP)
 
@@ -399,6 +458,14 @@ func (m *ExtendedMCPServerOptions) setupSIGHUPHandler(mcpServer *mcp.Server) {
 				continue
 			}
 
+			// Apply the new configuration to the provider first, so that a failure (e.g. unreadable keys) keeps the previous one
+			if reloader, ok := provider.(extkubernetes.ConfigReloader); ok {
+				if err := reloader.ReloadConfig(newConfig); err != nil {
+					klog.Errorf("Failed to apply reloaded configuration to the cluster provider: %v", err)
+					continue
+				}
+			}
+
 			// Apply the new configuration to the MCP server
 			if err := mcpServer.ReloadConfiguration(newConfig); err != nil {
 				klog.Errorf("Failed to apply reloaded configuration: %v", err)
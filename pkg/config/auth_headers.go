@@ -0,0 +1,434 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	kmsapi "github.com/containers/kubernetes-mcp-server/pkg/api"
+	kmsconfig "github.com/containers/kubernetes-mcp-server/pkg/config"
+	"github.com/containers/kubernetes-mcp-server/pkg/tokenexchange"
+)
+
+const (
+	// DefaultClientCacheTTL is the default lifetime of a cached derived Kubernetes client.
+	DefaultClientCacheTTL = 10 * time.Minute
+	// DefaultClientCacheMaxEntries is the default maximum number of cached derived Kubernetes clients.
+	DefaultClientCacheMaxEntries = 100
+
+	// DefaultQPS is the default sustained queries per second to a target cluster.
+	// Tool calls issued by LLM agents come in bursts (e.g. listing many resources at once), so it is
+	// higher than the client-go default (5).
+	DefaultQPS = 50
+	// DefaultBurst is the default burst of queries to a target cluster (client-go default is 10).
+	DefaultBurst = 100
+	// DefaultTimeout is the default timeout of a single request to a target cluster.
+	DefaultTimeout = 30 * time.Second
+	// DefaultUserAgent is the default user agent sent to the target clusters.
+	DefaultUserAgent = "ext-kyma-mcp/auth-headers"
+
+	// TokenReviewModeTokenReview verifies tokens with the authentication.k8s.io TokenReview API.
+	TokenReviewModeTokenReview = "token-review"
+	// TokenReviewModeSelfSubjectReview verifies tokens with the authentication.k8s.io SelfSubjectReview API.
+	TokenReviewModeSelfSubjectReview = "self-subject-review"
+
+	// InsecureSkipTLSVerifyForbid rejects requests asking to skip TLS verification.
+	InsecureSkipTLSVerifyForbid = "forbid"
+	// InsecureSkipTLSVerifyAllow lets callers skip TLS verification for any server.
+	InsecureSkipTLSVerifyAllow = "allow"
+	// InsecureSkipTLSVerifyAllowlisted lets callers skip TLS verification only for servers in InsecureSkipTLSVerifyServers.
+	InsecureSkipTLSVerifyAllowlisted = "allowlisted"
+)
+
+// AuthHeadersProviderConfig holds the auth-headers cluster provider configuration.
+//
+// It is read from the [cluster_provider_configs.auth-headers] TOML section.
+type AuthHeadersProviderConfig struct {
+	// ClientCacheTTL is how long a derived Kubernetes client is reused for identical auth headers (e.g. "10m").
+	// Set to "0s" to disable the client cache.
+	ClientCacheTTL string `toml:"client_cache_ttl,omitempty"`
+	// ClientCacheMaxEntries is the maximum number of derived Kubernetes clients kept in memory.
+	ClientCacheMaxEntries int `toml:"client_cache_max_entries,omitempty"`
+	// TokenReviewMode is how bearer tokens are verified against the target cluster when require_oauth is enabled.
+	// One of "token-review" (default) or "self-subject-review".
+	// TokenReview validates the oauth_audience but requires every caller to be allowed to create tokenreviews
+	// (system:auth-delegator). SelfSubjectReview works for any authenticated user but cannot validate audiences,
+	// so it cannot be used with oauth_audience. Successful verifications are reused for a minute.
+	TokenReviewMode string `toml:"token_review_mode,omitempty"`
+	// AllowedServers restricts the target servers to the listed exact hosts, domain suffixes (e.g. "*.kyma.ondemand.com")
+	// or CIDRs (e.g. "10.250.0.0/16"). If empty, all servers are allowed unless denied.
+	AllowedServers []string `toml:"allowed_servers,omitempty"`
+	// DeniedServers rejects the listed exact hosts, domain suffixes or CIDRs. It takes precedence over AllowedServers.
+	DeniedServers []string `toml:"denied_servers,omitempty"`
+	// AllowPrivateNetworks allows target servers resolving to loopback, private, link-local or shared address ranges.
+	// By default they are rejected, unless the address is explicitly allowed by a CIDR in AllowedServers.
+	// Cloud metadata endpoints are always rejected.
+	AllowPrivateNetworks bool `toml:"allow_private_networks,omitempty"`
+	// InsecureSkipTLSVerify controls whether callers may skip TLS verification of the target server.
+	// One of "forbid" (default), "allow" or "allowlisted".
+	InsecureSkipTLSVerify string `toml:"insecure_skip_tls_verify,omitempty"`
+	// InsecureSkipTLSVerifyServers lists the exact hosts, domain suffixes or CIDRs for which TLS verification
+	// may be skipped when InsecureSkipTLSVerify is "allowlisted".
+	InsecureSkipTLSVerifyServers []string `toml:"insecure_skip_tls_verify_servers,omitempty"`
+	// EnvelopeDecryptionKeyFiles lists the files holding the server keys used to decrypt JWE envelopes
+	// (PEM private keys, or JWK / JWK set documents, which also allow symmetric AES keys).
+	// Several keys can be listed to rotate them. Relative paths are resolved against the configuration directory.
+	EnvelopeDecryptionKeyFiles []string `toml:"envelope_decryption_key_files,omitempty"`
+	// EnvelopeVerificationKeyFiles lists the files holding the public keys of the trusted gateways used to verify
+	// JWS envelopes (PEM public keys or certificates, or JWK / JWK set documents).
+	EnvelopeVerificationKeyFiles []string `toml:"envelope_verification_key_files,omitempty"`
+	// RequireProtectedEnvelope rejects plain JSON envelopes, only encrypted (JWE) or signed (JWS) envelopes are accepted.
+	RequireProtectedEnvelope bool `toml:"require_protected_envelope,omitempty"`
+	// ImpersonationEnabled allows callers to impersonate users and groups with the x-target-k8s-impersonate-* keys.
+	// Requests asking for impersonation are rejected when disabled (default).
+	ImpersonationEnabled bool `toml:"impersonation_enabled,omitempty"`
+	// ImpersonationAllowedGroups restricts the groups that may be impersonated to the listed names, a trailing "*"
+	// matches a prefix (e.g. "team-*"). If empty, any group may be impersonated.
+	ImpersonationAllowedGroups []string `toml:"impersonation_allowed_groups,omitempty"`
+	// ProxyURL is the default proxy (http, https, socks5 or socks5h URL) used to reach the target clusters,
+	// e.g. a corporate proxy or a connectivity-proxy tunnel.
+	ProxyURL string `toml:"proxy_url,omitempty"`
+	// ForbidProxyURLOverride rejects requests setting their own proxy with x-target-k8s-proxy-url (or the
+	// kubeconfig proxy-url). Per-request proxies are otherwise checked against the server allowlist and denylist.
+	ForbidProxyURLOverride bool `toml:"forbid_proxy_url_override,omitempty"`
+	// QPS is the sustained queries per second to a target cluster (defaults to 50).
+	QPS float32 `toml:"qps,omitempty"`
+	// Burst is the burst of queries to a target cluster (defaults to 100).
+	Burst int `toml:"burst,omitempty"`
+	// Timeout is the timeout of a single request to a target cluster (e.g. "30s", defaults to 30s).
+	Timeout string `toml:"timeout,omitempty"`
+	// UserAgent is the user agent sent to the target clusters (defaults to ext-kyma-mcp/auth-headers).
+	UserAgent string `toml:"user_agent,omitempty"`
+	// TokenExchange enables the exchange of the caller (IdP) token for a cluster-scoped token.
+	// It is read from the [cluster_provider_configs.auth-headers.token_exchange] TOML section.
+	TokenExchange *AuthHeadersTokenExchangeConfig `toml:"token_exchange,omitempty"`
+	// Targets are the target names advertised in the tool target parameter, for multi-target envelopes
+	// ({"targets": {"dev": {...}, "prod": {...}}}). If empty, tools have no target parameter and use the
+	// envelope default target.
+	Targets []string `toml:"targets,omitempty"`
+	// DefaultTarget is the target used when the tool call does not select one. Defaults to the first of Targets.
+	DefaultTarget string `toml:"default_target,omitempty"`
+
+	clientCacheTTL time.Duration
+	timeout        time.Duration
+}
+
+// AuthHeadersTokenExchangeConfig configures the token exchange (RFC 8693 or Keycloak V1) of the auth-headers provider.
+//
+// The bearer token of the auth headers is sent as subject token to the token endpoint, which is either
+// token_url, discovered from issuer_url, or discovered from the issuer of the target cluster.
+type AuthHeadersTokenExchangeConfig struct {
+	tokenexchange.TargetTokenExchangeConfig
+	// Strategy is the token exchange strategy, "rfc8693" (default) or "keycloak-v1".
+	Strategy string `toml:"strategy,omitempty"`
+	// IssuerURL is the OIDC issuer whose token endpoint is discovered when token_url is not set.
+	IssuerURL string `toml:"issuer_url,omitempty"`
+	// UseClusterIssuer discovers the OIDC issuer of the target cluster (from its /.well-known/openid-configuration)
+	// when neither token_url nor issuer_url are set.
+	UseClusterIssuer bool `toml:"use_cluster_issuer,omitempty"`
+	// AllowedClusterIssuers are the hosts (exact host, "*.domain" suffix or CIDR) the issuers discovered from the
+	// target clusters and their token endpoints must match. Required with use_cluster_issuer, since the subject
+	// token and the client credentials are sent to the token endpoint of the discovered issuer.
+	AllowedClusterIssuers []string `toml:"allowed_cluster_issuers,omitempty"`
+}
+
+// Validate checks that the token exchange configuration is valid.
+func (c *AuthHeadersTokenExchangeConfig) Validate() error {
+	if err := c.TargetTokenExchangeConfig.Validate(); err != nil {
+		return err
+	}
+	if _, ok := tokenexchange.GetTokenExchanger(c.GetStrategy()); !ok {
+		return fmt.Errorf("token_exchange strategy %q is not supported", c.Strategy)
+	}
+	if c.TokenURL == "" && c.IssuerURL == "" && !c.UseClusterIssuer {
+		return errors.New("token_exchange requires token_url, issuer_url or use_cluster_issuer")
+	}
+	if c.UseClusterIssuer && len(c.AllowedClusterIssuers) == 0 {
+		return errors.New("token_exchange use_cluster_issuer requires allowed_cluster_issuers")
+	}
+	for _, issuer := range c.AllowedClusterIssuers {
+		if err := ValidateServerPattern(issuer); err != nil {
+			return fmt.Errorf("invalid allowed_cluster_issuers entry: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetStrategy returns the configured token exchange strategy or the default one.
+func (c *AuthHeadersTokenExchangeConfig) GetStrategy() string {
+	if c.Strategy == "" {
+		return tokenexchange.StrategyRFC8693
+	}
+	return c.Strategy
+}
+
+// GetSubjectTokenType returns the configured subject token type or the default access token type.
+func (c *AuthHeadersTokenExchangeConfig) GetSubjectTokenType() string {
+	if c.SubjectTokenType == "" {
+		return tokenexchange.TokenTypeAccessToken
+	}
+	return c.SubjectTokenType
+}
+
+var _ kmsapi.ExtendedConfig = (*AuthHeadersProviderConfig)(nil)
+
+func (c *AuthHeadersProviderConfig) Validate() error {
+	if c == nil {
+		return errors.New("auth-headers config is nil")
+	}
+	if c.ClientCacheTTL != "" {
+		ttl, err := time.ParseDuration(c.ClientCacheTTL)
+		if err != nil {
+			return fmt.Errorf("client_cache_ttl must be a valid duration: %w", err)
+		}
+		if ttl < 0 {
+			return errors.New("client_cache_ttl must not be negative")
+		}
+		c.clientCacheTTL = ttl
+	}
+	if c.ClientCacheMaxEntries < 0 {
+		return errors.New("client_cache_max_entries must not be negative")
+	}
+	for _, server := range c.AllowedServers {
+		if err := ValidateServerPattern(server); err != nil {
+			return fmt.Errorf("invalid allowed_servers entry: %w", err)
+		}
+	}
+	for _, server := range c.DeniedServers {
+		if err := ValidateServerPattern(server); err != nil {
+			return fmt.Errorf("invalid denied_servers entry: %w", err)
+		}
+	}
+	for _, server := range c.InsecureSkipTLSVerifyServers {
+		if err := ValidateServerPattern(server); err != nil {
+			return fmt.Errorf("invalid insecure_skip_tls_verify_servers entry: %w", err)
+		}
+	}
+	if c.RequireProtectedEnvelope && len(c.EnvelopeDecryptionKeyFiles) == 0 && len(c.EnvelopeVerificationKeyFiles) == 0 {
+		return errors.New("require_protected_envelope needs envelope_decryption_key_files or envelope_verification_key_files")
+	}
+	for _, group := range c.ImpersonationAllowedGroups {
+		if strings.TrimSpace(group) == "" || strings.Contains(strings.TrimSuffix(group, "*"), "*") {
+			return fmt.Errorf("invalid impersonation_allowed_groups entry %q", group)
+		}
+	}
+	if c.ProxyURL != "" {
+		if err := ValidateProxyURL(c.ProxyURL); err != nil {
+			return fmt.Errorf("invalid proxy_url: %w", err)
+		}
+	}
+	if c.QPS < 0 || c.Burst < 0 {
+		return errors.New("qps and burst must not be negative")
+	}
+	if c.Timeout != "" {
+		timeout, err := time.ParseDuration(c.Timeout)
+		if err != nil {
+			return fmt.Errorf("timeout must be a valid duration: %w", err)
+		}
+		if timeout < 0 {
+			return errors.New("timeout must not be negative")
+		}
+		c.timeout = timeout
+	}
+	if c.TokenExchange != nil {
+		if err := c.TokenExchange.Validate(); err != nil {
+			return fmt.Errorf("invalid token_exchange: %w", err)
+		}
+	}
+	for _, target := range c.Targets {
+		if strings.TrimSpace(target) == "" {
+			return errors.New("targets must not contain empty names")
+		}
+	}
+	if c.DefaultTarget != "" && len(c.Targets) > 0 && !slices.Contains(c.Targets, c.DefaultTarget) {
+		return fmt.Errorf("default_target %q must be one of targets", c.DefaultTarget)
+	}
+	switch c.InsecureSkipTLSVerify {
+	case "", InsecureSkipTLSVerifyForbid, InsecureSkipTLSVerifyAllow, InsecureSkipTLSVerifyAllowlisted:
+	default:
+		return fmt.Errorf("insecure_skip_tls_verify must be one of: %s, %s, %s", InsecureSkipTLSVerifyForbid, InsecureSkipTLSVerifyAllow, InsecureSkipTLSVerifyAllowlisted)
+	}
+	switch c.TokenReviewMode {
+	case "", TokenReviewModeTokenReview, TokenReviewModeSelfSubjectReview:
+	default:
+		return fmt.Errorf("token_review_mode must be one of: %s, %s", TokenReviewModeTokenReview, TokenReviewModeSelfSubjectReview)
+	}
+	return nil
+}
+
+// GetClientCacheTTL returns the configured client cache TTL or the default one.
+func (c *AuthHeadersProviderConfig) GetClientCacheTTL() time.Duration {
+	if c.ClientCacheTTL == "" {
+		return DefaultClientCacheTTL
+	}
+	return c.clientCacheTTL
+}
+
+// GetClientCacheMaxEntries returns the configured client cache size or the default one.
+func (c *AuthHeadersProviderConfig) GetClientCacheMaxEntries() int {
+	if c.ClientCacheMaxEntries == 0 {
+		return DefaultClientCacheMaxEntries
+	}
+	return c.ClientCacheMaxEntries
+}
+
+// ValidateServerPattern validates a target server pattern: an exact host, a domain suffix ("*.example.com") or a CIDR.
+func ValidateServerPattern(pattern string) error {
+	pattern = strings.TrimSpace(pattern)
+	switch {
+	case pattern == "":
+		return errors.New("server pattern must not be empty")
+	case strings.Contains(pattern, "/"):
+		if _, _, err := net.ParseCIDR(pattern); err != nil {
+			return fmt.Errorf("%q is not a valid CIDR: %w", pattern, err)
+		}
+	case strings.HasPrefix(pattern, "*."):
+		if strings.Contains(strings.TrimPrefix(pattern, "*."), "*") || len(pattern) == len("*.") {
+			return fmt.Errorf("%q is not a valid domain suffix", pattern)
+		}
+	case strings.ContainsAny(pattern, "*:"):
+		if net.ParseIP(pattern) == nil {
+			return fmt.Errorf("%q is not a valid host", pattern)
+		}
+	}
+	return nil
+}
+
+// GetTokenReviewMode returns the configured token review mode or the default one.
+func (c *AuthHeadersProviderConfig) GetTokenReviewMode() string {
+	if c.TokenReviewMode == "" {
+		return TokenReviewModeTokenReview
+	}
+	return c.TokenReviewMode
+}
+
+// GetInsecureSkipTLSVerify returns the configured insecure TLS policy or the default one.
+func (c *AuthHeadersProviderConfig) GetInsecureSkipTLSVerify() string {
+	if c.InsecureSkipTLSVerify == "" {
+		return InsecureSkipTLSVerifyForbid
+	}
+	return c.InsecureSkipTLSVerify
+}
+
+// GetQPS returns the configured QPS or the default one.
+func (c *AuthHeadersProviderConfig) GetQPS() float32 {
+	if c.QPS == 0 {
+		return DefaultQPS
+	}
+	return c.QPS
+}
+
+// GetBurst returns the configured burst or the default one.
+func (c *AuthHeadersProviderConfig) GetBurst() int {
+	if c.Burst == 0 {
+		return DefaultBurst
+	}
+	return c.Burst
+}
+
+// GetTimeout returns the configured request timeout or the default one.
+func (c *AuthHeadersProviderConfig) GetTimeout() time.Duration {
+	if c.Timeout == "" {
+		return DefaultTimeout
+	}
+	return c.timeout
+}
+
+// GetUserAgent returns the configured user agent or the default one.
+func (c *AuthHeadersProviderConfig) GetUserAgent() string {
+	if c.UserAgent == "" {
+		return DefaultUserAgent
+	}
+	return c.UserAgent
+}
+
+// ValidateProxyURL checks that the proxy URL is an absolute http, https, socks5 or socks5h URL.
+func ValidateProxyURL(proxyURL string) error {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return err
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return fmt.Errorf("unsupported proxy scheme %q, must be one of http, https, socks5, socks5h", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return errors.New("proxy URL must have a host")
+	}
+	return nil
+}
+
+// IsImpersonationGroupAllowed returns true if the group may be impersonated.
+func (c *AuthHeadersProviderConfig) IsImpersonationGroupAllowed(group string) bool {
+	if len(c.ImpersonationAllowedGroups) == 0 {
+		return true
+	}
+	for _, allowed := range c.ImpersonationAllowedGroups {
+		if prefix, isPrefix := strings.CutSuffix(allowed, "*"); isPrefix && strings.HasPrefix(group, prefix) || allowed == group {
+			return true
+		}
+	}
+	return false
+}
+
+// GetDefaultTarget returns the configured default target or the first configured target.
+func (c *AuthHeadersProviderConfig) GetDefaultTarget() string {
+	if c.DefaultTarget == "" && len(c.Targets) > 0 {
+		return c.Targets[0]
+	}
+	return c.DefaultTarget
+}
+
+// GetAuthHeadersProviderConfig returns the auth-headers provider configuration.
+// If the configuration is not set, a configuration with default values is returned.
+func GetAuthHeadersProviderConfig(provider kmsapi.ExtendedConfigProvider) *AuthHeadersProviderConfig {
+	if provider != nil {
+		if cfg, ok := provider.GetProviderConfig(ClusterProviderAuthHeaders); ok {
+			if ahc, ok := cfg.(*AuthHeadersProviderConfig); ok && ahc != nil {
+				return ahc
+			}
+		}
+	}
+	return &AuthHeadersProviderConfig{}
+}
+
+func authHeadersProviderParser(ctx context.Context, primitive toml.Primitive, md toml.MetaData) (kmsapi.ExtendedConfig, error) {
+	var cfg AuthHeadersProviderConfig
+	if err := md.PrimitiveDecode(primitive, &cfg); err != nil {
+		return nil, err
+	}
+	configDirPath := kmsconfig.ConfigDirPathFromContext(ctx)
+	cfg.EnvelopeDecryptionKeyFiles = resolvePaths(configDirPath, cfg.EnvelopeDecryptionKeyFiles)
+	cfg.EnvelopeVerificationKeyFiles = resolvePaths(configDirPath, cfg.EnvelopeVerificationKeyFiles)
+	if cfg.TokenExchange != nil && cfg.TokenExchange.CAFile != "" {
+		cfg.TokenExchange.CAFile = resolvePaths(configDirPath, []string{cfg.TokenExchange.CAFile})[0]
+	}
+	return &cfg, nil
+}
+
+// resolvePaths resolves the relative paths against the configuration directory.
+func resolvePaths(configDirPath string, paths []string) []string {
+	if configDirPath == "" {
+		return paths
+	}
+	ret := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if path != "" && !filepath.IsAbs(path) {
+			path = filepath.Join(configDirPath, path)
+		}
+		ret = append(ret, path)
+	}
+	return ret
+}
+
+func init() {
+	kmsconfig.RegisterProviderConfig(ClusterProviderAuthHeaders, authHeadersProviderParser)
+}
@@ -0,0 +1,82 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+
+	kmsconfig "github.com/containers/kubernetes-mcp-server/pkg/config"
+	internalhttp "github.com/containers/kubernetes-mcp-server/pkg/http"
+	kmskubernetes "github.com/containers/kubernetes-mcp-server/pkg/kubernetes"
+	"github.com/mfaizanse/ext-kyma-mcp/pkg/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// write401 sends a 401/Unauthorized response with WWW-Authenticate header.
+func write401(w http.ResponseWriter, wwwAuthenticateHeader, errorType, message string) {
+	w.Header().Set("WWW-Authenticate", wwwAuthenticateHeader+fmt.Sprintf(`, error="%s"`, errorType))
+	http.Error(w, message, http.StatusUnauthorized)
+}
+
+// AuthHeadersAuthorizationMiddleware validates the bearer token carried in the auth headers payload.
+//
+// The flow is skipped for unprotected resources, such as health checks and well-known endpoints,
+// and when requireOAuth is false.
+//
+// Otherwise, the auth headers are parsed (and the envelope opened) from the Authorization header and the bearer token is verified
+// against the target cluster (TokenReview, or SelfSubjectReview without audience), so that forged or expired tokens are
+// rejected before any tool runs. Successful verifications are reused for a short time.
+func AuthHeadersAuthorizationMiddleware(staticConfig *kmsconfig.StaticConfig, verifier kubernetes.TokenVerifier, parser kubernetes.AuthHeadersParser) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == healthEndpoint || slices.Contains(internalhttp.WellKnownEndpoints, r.URL.EscapedPath()) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if !staticConfig.RequireOAuth {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			wwwAuthenticateHeader := "Bearer realm=\"Kubernetes MCP Server\""
+			if staticConfig.OAuthAudience != "" {
+				wwwAuthenticateHeader += fmt.Sprintf(`, audience="%s"`, staticConfig.OAuthAudience)
+			}
+
+			authData := r.Header.Get(string(kmskubernetes.OAuthAuthorizationHeader))
+			if authData == "" {
+				klog.V(1).Infof("Authentication failed - missing auth headers: %s %s from %s", r.Method, r.URL.Path, r.RemoteAddr)
+				write401(w, wwwAuthenticateHeader, "missing_token", "Unauthorized: auth headers required")
+				return
+			}
+
+			authTargets, err := parser.ParseAuthTargets(authData)
+			if err != nil {
+				klog.V(1).Infof("Authentication failed - invalid auth headers: %s %s from %s, error: %v", r.Method, r.URL.Path, r.RemoteAddr, err)
+				write401(w, wwwAuthenticateHeader, "invalid_request", "Unauthorized: Invalid auth headers")
+				return
+			}
+
+			// Every target of a multi-target envelope must carry a valid token
+			ctx := context.WithValue(r.Context(), kmskubernetes.OAuthAuthorizationHeader, authData)
+			for _, target := range authTargets.Names() {
+				authHeaders := authTargets.Targets[target]
+				if authHeaders.AuthorizationToken == "" {
+					klog.V(1).Infof("Authentication failed - missing bearer token in auth headers of target %q: %s %s from %s", target, r.Method, r.URL.Path, r.RemoteAddr)
+					write401(w, wwwAuthenticateHeader, "missing_token", fmt.Sprintf("Unauthorized: %s is required", kubernetes.CustomAuthorizationHeader))
+					return
+				}
+				userInfo, _, err := verifier.VerifyToken(ctx, target, authHeaders.AuthorizationToken, staticConfig.OAuthAudience)
+				if err != nil {
+					klog.V(1).Infof("Authentication failed - token review error for target %q: %s %s from %s, error: %v", target, r.Method, r.URL.Path, r.RemoteAddr, err)
+					write401(w, wwwAuthenticateHeader, "invalid_token", "Unauthorized: Invalid token")
+					return
+				}
+				klog.V(5).Infof("Authenticated %s on target %q for %s %s", userInfo.Username, target, r.Method, r.URL.Path)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package http
+
+import (
+	"net/http"
+
+	kmskubernetes "github.com/containers/kubernetes-mcp-server/pkg/kubernetes"
+	"github.com/mfaizanse/ext-kyma-mcp/pkg/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// AuthHeadersPropagationMiddleware lifts discrete X-Target-K8s-* request headers into the auth headers payload.
+//
+// The MCP server only propagates the Authorization header into the tool call context, so the discrete
+// headers are merged (see kubernetes.NewAuthDataFromHTTPHeaders for the precedence) into a base64-encoded
+// JSON payload which replaces the Authorization header.
+func AuthHeadersPropagationMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizationHeader := string(kmskubernetes.OAuthAuthorizationHeader)
+		authData, err := kubernetes.NewAuthDataFromHTTPHeaders(r.Header.Get(authorizationHeader), r.Header)
+		if err != nil {
+			klog.V(1).Infof("Invalid auth headers: %s %s from %s, error: %v", r.Method, r.URL.Path, r.RemoteAddr, err)
+			http.Error(w, "Bad Request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if authData != "" {
+			r = r.Clone(r.Context())
+			r.Header.Set(authorizationHeader, authData)
+			for _, key := range kubernetes.AuthHeaderKeys {
+				r.Header.Del(string(key))
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,187 @@
+package kubernetes
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"sync"
+	"time"
+
+	kmskubernetes "github.com/containers/kubernetes-mcp-server/pkg/kubernetes"
+	authenticationv1api "k8s.io/api/authentication/v1"
+)
+
+const (
+	// verifiedSubjectsTTL is how long a verified subject is remembered for auditing.
+	verifiedSubjectsTTL = 10 * time.Minute
+	// verifiedSubjectsMaxEntries is the maximum number of verified subjects kept in memory for auditing.
+	verifiedSubjectsMaxEntries = 1000
+
+	// tokenVerificationsTTL is how long a successful token verification is reused by the authorization middleware.
+	// It is short so that revoked tokens are rejected soon.
+	tokenVerificationsTTL = time.Minute
+	// tokenVerificationsMaxEntries is the maximum number of token verifications kept in memory.
+	tokenVerificationsMaxEntries = 1000
+)
+
+// AuditInfoResolver is implemented by providers that can tell the target server and the authenticated
+// subject of a tool call for the audit log.
+type AuditInfoResolver interface {
+	AuditInfo(ctx context.Context, target string) (server, subject string)
+}
+
+var _ AuditInfoResolver = &AuthHeadersClusterProvider{}
+
+// Rejection describes a request whose auth headers were rejected (e.g. by the server policy, the impersonation
+// restrictions, the envelope verification or the token verification) before any tool handler ran.
+type Rejection struct {
+	Target string
+	// Server is the host of the target server, empty if the auth headers could not be parsed.
+	Server string
+	Reason string
+}
+
+// RejectionReporter is implemented by providers that report the rejected auth headers for the audit log.
+type RejectionReporter interface {
+	SetRejectionHandler(handler func(ctx context.Context, rejection *Rejection))
+}
+
+var _ RejectionReporter = &AuthHeadersClusterProvider{}
+
+type verifiedSubject struct {
+	username  string
+	expiresAt time.Time
+}
+
+// verifiedSubjects remembers the usernames of the tokens verified by the authorization middleware,
+// so that the audit log can tell who ran a tool without reviewing the token again.
+type verifiedSubjects struct {
+	mu      sync.Mutex
+	entries map[string]*verifiedSubject
+}
+
+func newVerifiedSubjects() *verifiedSubjects {
+	return &verifiedSubjects{entries: make(map[string]*verifiedSubject)}
+}
+
+func (s *verifiedSubjects) store(server, token, username string) {
+	if s == nil || username == "" {
+		return
+	}
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.entries) >= verifiedSubjectsMaxEntries {
+		for key, entry := range s.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(s.entries, key)
+			}
+		}
+		if len(s.entries) >= verifiedSubjectsMaxEntries {
+			s.entries = make(map[string]*verifiedSubject)
+		}
+	}
+	s.entries[verifiedSubjectKey(server, token)] = &verifiedSubject{username: username, expiresAt: now.Add(verifiedSubjectsTTL)}
+}
+
+func (s *verifiedSubjects) get(server, token string) string {
+	if s == nil {
+		return ""
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[verifiedSubjectKey(server, token)]
+	if !ok || !time.Now().Before(entry.expiresAt) {
+		return ""
+	}
+	return entry.username
+}
+
+// tokenVerification is the result of a successful token verification.
+type tokenVerification struct {
+	userInfo  *authenticationv1api.UserInfo
+	audiences []string
+}
+
+// tokenVerificationKey identifies the verification of a token of a server for an audience and a review mode,
+// the token is never stored in cleartext.
+func tokenVerificationKey(server, token, audience, mode string) string {
+	digest := sha256.New()
+	writeField(digest, []byte(server))
+	writeField(digest, []byte(token))
+	writeField(digest, []byte(audience))
+	writeField(digest, []byte(mode))
+	return hex.EncodeToString(digest.Sum(nil))
+}
+
+// verifiedSubjectKey identifies a token of a server, the token is never stored in cleartext.
+func verifiedSubjectKey(server, token string) string {
+	digest := sha256.New()
+	writeField(digest, []byte(server))
+	writeField(digest, []byte(token))
+	return hex.EncodeToString(digest.Sum(nil))
+}
+
+// AuditInfo returns the host of the target server and the subject verified for the auth headers in the
+// context. The subject is empty if the token was not verified (e.g. require_oauth is disabled), and
+// carries the impersonated user if any.
+func (p *AuthHeadersClusterProvider) AuditInfo(ctx context.Context, target string) (server, subject string) {
+	authData, ok := ctx.Value(kmskubernetes.OAuthAuthorizationHeader).(string)
+	if !ok || authData == "" {
+		return "", ""
+	}
+	authTargets, err := p.ParseAuthTargets(authData)
+	if err != nil {
+		return "", ""
+	}
+	authHeaders, err := authTargets.Get(target, p.GetDefaultTarget())
+	if err != nil {
+		return "", ""
+	}
+
+	server = serverHost(authHeaders.Server)
+
+	p.mu.RLock()
+	subjects := p.subjects
+	p.mu.RUnlock()
+	subject = subjects.get(authHeaders.Server, authHeaders.AuthorizationToken)
+	if authHeaders.IsImpersonating() {
+		if subject == "" {
+			subject = "unknown"
+		}
+		subject += " as " + authHeaders.ImpersonateUser
+	}
+	return server, subject
+}
+
+// SetRejectionHandler sets the handler called for every rejected request, it is kept across configuration reloads.
+func (p *AuthHeadersClusterProvider) SetRejectionHandler(handler func(ctx context.Context, rejection *Rejection)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.rejectionHandler = handler
+}
+
+// reject reports the rejection of the auth headers (nil if they could not be parsed) and returns the error.
+func (p *AuthHeadersClusterProvider) reject(ctx context.Context, target string, authHeaders *K8sAuthHeaders, err error) error {
+	p.mu.RLock()
+	handler := p.rejectionHandler
+	p.mu.RUnlock()
+	if handler == nil {
+		return err
+	}
+	rejection := &Rejection{Target: target, Reason: err.Error()}
+	if authHeaders != nil {
+		rejection.Server = serverHost(authHeaders.Server)
+	}
+	handler(ctx, rejection)
+	return err
+}
+
+// serverHost returns the host of the server URL, or the server as is if it is not a URL.
+func serverHost(server string) string {
+	if u, err := url.Parse(server); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return server
+}
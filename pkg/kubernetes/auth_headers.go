@@ -4,9 +4,11 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"strings"
 
 	kmskubernetes "github.com/containers/kubernetes-mcp-server/pkg/kubernetes"
+	"github.com/mfaizanse/ext-kyma-mcp/pkg/config"
 )
 
 // AuthType represents the type of Kubernetes authentication.
@@ -27,8 +29,41 @@ const (
 	CustomClientKeyDataHeader = kmskubernetes.HeaderKey("x-target-k8s-client-key-data")
 	// CustomInsecureSkipTLSVerify is the optional flag to skip TLS verification.
 	CustomInsecureSkipTLSVerifyHeader = kmskubernetes.HeaderKey("x-target-k8s-insecure-skip-tls-verify")
+	// CustomTLSServerNameHeader is the optional server name used to verify the server certificate.
+	CustomTLSServerNameHeader = kmskubernetes.HeaderKey("x-target-k8s-tls-server-name")
+	// CustomProxyURLHeader is the optional http, https or socks5 proxy used to reach the cluster.
+	CustomProxyURLHeader = kmskubernetes.HeaderKey("x-target-k8s-proxy-url")
+
+	// CustomImpersonateUserHeader is the optional user to impersonate.
+	CustomImpersonateUserHeader = kmskubernetes.HeaderKey("x-target-k8s-impersonate-user")
+	// CustomImpersonateGroupHeader is the optional list (or comma-separated string) of groups to impersonate.
+	CustomImpersonateGroupHeader = kmskubernetes.HeaderKey("x-target-k8s-impersonate-group")
+	// CustomImpersonateUIDHeader is the optional UID to impersonate.
+	CustomImpersonateUIDHeader = kmskubernetes.HeaderKey("x-target-k8s-impersonate-uid")
+
+	// CustomKubeconfigHeader is the base64-encoded kubeconfig, an alternative to the discrete cluster and credential headers.
+	CustomKubeconfigHeader = kmskubernetes.HeaderKey("x-target-k8s-kubeconfig")
+	// CustomContextHeader is the optional kubeconfig context to use (defaults to the kubeconfig current-context).
+	CustomContextHeader = kmskubernetes.HeaderKey("x-target-k8s-context")
 )
 
+// AuthHeaderKeys are all the keys supported in the auth headers payload and as discrete HTTP headers.
+var AuthHeaderKeys = []kmskubernetes.HeaderKey{
+	CustomServerHeader,
+	CustomCertificateAuthorityDataHeader,
+	CustomAuthorizationHeader,
+	CustomClientCertificateDataHeader,
+	CustomClientKeyDataHeader,
+	CustomInsecureSkipTLSVerifyHeader,
+	CustomTLSServerNameHeader,
+	CustomProxyURLHeader,
+	CustomImpersonateUserHeader,
+	CustomImpersonateGroupHeader,
+	CustomImpersonateUIDHeader,
+	CustomKubeconfigHeader,
+	CustomContextHeader,
+}
+
 // K8sAuthHeaders represents Kubernetes API authentication headers.
 type K8sAuthHeaders struct {
 	// Server is the Kubernetes cluster URL.
@@ -43,6 +78,16 @@ type K8sAuthHeaders struct {
 	ClientKeyData []byte
 	// InsecureSkipTLSVerify is the optional flag to skip TLS verification.
 	InsecureSkipTLSVerify bool
+	// TLSServerName is the optional server name used to verify the server certificate.
+	TLSServerName string
+	// ProxyURL is the optional URL of the proxy used to reach the cluster.
+	ProxyURL string
+	// ImpersonateUser is the optional user to impersonate.
+	ImpersonateUser string
+	// ImpersonateGroups are the optional groups to impersonate.
+	ImpersonateGroups []string
+	// ImpersonateUID is the optional UID to impersonate.
+	ImpersonateUID string
 }
 
 // GetDecodedData decodes and returns the data.
@@ -50,10 +95,8 @@ func GetDecodedData(data string) ([]byte, error) {
 	return base64.StdEncoding.DecodeString(data)
 }
 
-func NewK8sAuthHeadersFromString(data string) (*K8sAuthHeaders, error) {
-	var ok bool
-	var err error
-
+// DecodeAuthData decodes the auth headers payload (JSON or base64-encoded JSON) into a map with lower case keys.
+func DecodeAuthData(data string) (map[string]any, error) {
 	payload := []byte(data)
 	// check if data is base64-encoded string
 	decodedData, err := base64.StdEncoding.DecodeString(data)
@@ -73,7 +116,31 @@ func NewK8sAuthHeadersFromString(data string) (*K8sAuthHeaders, error) {
 	for k, v := range authDataMap {
 		authDataMapLower[strings.ToLower(k)] = v
 	}
-	authDataMap = authDataMapLower
+	return authDataMapLower, nil
+}
+
+func NewK8sAuthHeadersFromString(data string) (*K8sAuthHeaders, error) {
+	authDataMap, err := DecodeAuthData(data)
+	if err != nil {
+		return nil, err
+	}
+	return NewK8sAuthHeadersFromMap(authDataMap)
+}
+
+// NewK8sAuthHeadersFromMap parses the auth headers from a decoded payload with lower case keys.
+func NewK8sAuthHeadersFromMap(authDataMap map[string]any) (*K8sAuthHeaders, error) {
+	var ok bool
+	var err error
+
+	// A kubeconfig payload replaces the discrete cluster and credential headers.
+	if kubeconfigBase64, _ := authDataMap[string(CustomKubeconfigHeader)].(string); kubeconfigBase64 != "" {
+		kubeconfig, err := GetDecodedData(kubeconfigBase64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid kubeconfig data: %w", err)
+		}
+		contextName, _ := authDataMap[string(CustomContextHeader)].(string)
+		return NewK8sAuthHeadersFromKubeconfig(kubeconfig, contextName)
+	}
 
 	// Initialize auth headers with default values.
 	authHeaders := &K8sAuthHeaders{
@@ -98,8 +165,45 @@ func NewK8sAuthHeadersFromString(data string) (*K8sAuthHeaders, error) {
 	}
 
 	// Get insecure skip TLS verify flag from headers.
-	if authDataMap[string(CustomInsecureSkipTLSVerifyHeader)] != nil && strings.ToLower(authDataMap[string(CustomInsecureSkipTLSVerifyHeader)].(string)) == "true" {
-		authHeaders.InsecureSkipTLSVerify = true
+	switch insecureSkipTLSVerify := authDataMap[string(CustomInsecureSkipTLSVerifyHeader)].(type) {
+	case bool:
+		authHeaders.InsecureSkipTLSVerify = insecureSkipTLSVerify
+	case string:
+		authHeaders.InsecureSkipTLSVerify = strings.ToLower(insecureSkipTLSVerify) == "true"
+	}
+
+	// Get TLS server name override from headers.
+	authHeaders.TLSServerName, _ = authDataMap[string(CustomTLSServerNameHeader)].(string)
+
+	// Get proxy URL from headers.
+	authHeaders.ProxyURL, _ = authDataMap[string(CustomProxyURLHeader)].(string)
+	if authHeaders.ProxyURL != "" {
+		if err := config.ValidateProxyURL(authHeaders.ProxyURL); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", CustomProxyURLHeader, err)
+		}
+	}
+
+	// Get impersonation from headers.
+	authHeaders.ImpersonateUser, _ = authDataMap[string(CustomImpersonateUserHeader)].(string)
+	authHeaders.ImpersonateUID, _ = authDataMap[string(CustomImpersonateUIDHeader)].(string)
+	switch groups := authDataMap[string(CustomImpersonateGroupHeader)].(type) {
+	case string:
+		for _, group := range strings.Split(groups, ",") {
+			if group = strings.TrimSpace(group); group != "" {
+				authHeaders.ImpersonateGroups = append(authHeaders.ImpersonateGroups, group)
+			}
+		}
+	case []any:
+		for _, group := range groups {
+			value, ok := group.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s must be a list of strings", CustomImpersonateGroupHeader)
+			}
+			authHeaders.ImpersonateGroups = append(authHeaders.ImpersonateGroups, value)
+		}
+	}
+	if authHeaders.ImpersonateUser == "" && (len(authHeaders.ImpersonateGroups) > 0 || authHeaders.ImpersonateUID != "") {
+		return nil, fmt.Errorf("%s header is required to impersonate groups or a UID", CustomImpersonateUserHeader)
 	}
 
 	// Get authorization token from headers.
@@ -130,6 +234,11 @@ func NewK8sAuthHeadersFromString(data string) (*K8sAuthHeaders, error) {
 	return authHeaders, nil
 }
 
+// IsImpersonating returns true if the auth headers request impersonation.
+func (h *K8sAuthHeaders) IsImpersonating() bool {
+	return h.ImpersonateUser != ""
+}
+
 // IsValid checks if the authentication headers are valid.
 func (h *K8sAuthHeaders) IsValid() bool {
 	if h.AuthorizationToken != "" {
@@ -140,3 +249,25 @@ func (h *K8sAuthHeaders) IsValid() bool {
 	}
 	return false
 }
+
+// String returns the auth headers with the token, client key and CA data redacted, so that they are never
+// leaked by logs or the audit log.
+func (h *K8sAuthHeaders) String() string {
+	redacted := func(data []byte) string {
+		if len(data) == 0 {
+			return ""
+		}
+		return "[REDACTED]"
+	}
+	token := ""
+	if h.AuthorizationToken != "" {
+		token = "[REDACTED]"
+	}
+	proxyURL := h.ProxyURL
+	if u, err := url.Parse(h.ProxyURL); err == nil {
+		proxyURL = u.Redacted()
+	}
+	return fmt.Sprintf("{server: %q, token: %q, clientCertificate: %q, clientKey: %q, certificateAuthority: %q, insecureSkipTLSVerify: %t, tlsServerName: %q, proxyURL: %q, impersonateUser: %q, impersonateGroups: %q, impersonateUID: %q}",
+		h.Server, token, redacted(h.ClientCertificateData), redacted(h.ClientKeyData), redacted(h.CertificateAuthorityData),
+		h.InsecureSkipTLSVerify, h.TLSServerName, proxyURL, h.ImpersonateUser, h.ImpersonateGroups, h.ImpersonateUID)
+}
@@ -0,0 +1,232 @@
+package kubernetes
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/mfaizanse/ext-kyma-mcp/pkg/config"
+)
+
+var (
+	envelopeKeyAlgorithms = []jose.KeyAlgorithm{
+		jose.RSA_OAEP, jose.RSA_OAEP_256,
+		jose.ECDH_ES, jose.ECDH_ES_A128KW, jose.ECDH_ES_A192KW, jose.ECDH_ES_A256KW,
+		jose.A128KW, jose.A192KW, jose.A256KW, jose.A128GCMKW, jose.A192GCMKW, jose.A256GCMKW,
+		jose.DIRECT,
+	}
+	envelopeContentEncryptions  = []jose.ContentEncryption{jose.A128GCM, jose.A192GCM, jose.A256GCM}
+	envelopeSignatureAlgorithms = []jose.SignatureAlgorithm{
+		jose.RS256, jose.RS384, jose.RS512, jose.PS256, jose.PS384, jose.PS512,
+		jose.ES256, jose.ES384, jose.ES512, jose.EdDSA,
+	}
+)
+
+// EnvelopeKeys holds the key material used to open protected auth headers envelopes.
+//
+// A protected envelope is either a compact JWE encrypted to one of the server keys (e.g. RSA-OAEP or
+// a direct AES-GCM key), or a compact JWS signed by a trusted gateway. The payload of both is the usual
+// JSON auth headers payload. A JWE may also wrap a JWS (sign-then-encrypt).
+type EnvelopeKeys struct {
+	decryptionKeys   []jose.JSONWebKey
+	verificationKeys []jose.JSONWebKey
+	required         bool
+}
+
+// LoadEnvelopeKeys reads the envelope key files referenced in the auth-headers provider configuration.
+func LoadEnvelopeKeys(cfg *config.AuthHeadersProviderConfig) (*EnvelopeKeys, error) {
+	keys := &EnvelopeKeys{required: cfg.RequireProtectedEnvelope}
+	for _, file := range cfg.EnvelopeDecryptionKeyFiles {
+		fileKeys, err := loadKeyFile(file, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load envelope decryption key %s: %w", file, err)
+		}
+		keys.decryptionKeys = append(keys.decryptionKeys, fileKeys...)
+	}
+	for _, file := range cfg.EnvelopeVerificationKeyFiles {
+		fileKeys, err := loadKeyFile(file, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load envelope verification key %s: %w", file, err)
+		}
+		keys.verificationKeys = append(keys.verificationKeys, fileKeys...)
+	}
+	return keys, nil
+}
+
+// IsProtectedEnvelope returns true if the auth headers payload looks like a compact JWE or JWS.
+func IsProtectedEnvelope(data string) bool {
+	data = strings.TrimSpace(data)
+	switch strings.Count(data, ".") {
+	case 2, 4:
+	default:
+		return false
+	}
+	// compact serializations only contain base64url segments, unlike plain JSON payloads
+	return strings.IndexFunc(data, func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '-' && r != '_' && r != '.'
+	}) < 0
+}
+
+// Open returns the plain auth headers payload of the envelope.
+// Plain payloads are returned as-is unless a protected envelope is required.
+func (k *EnvelopeKeys) Open(data string) (string, error) {
+	data = strings.TrimSpace(data)
+	if !IsProtectedEnvelope(data) {
+		if k != nil && k.required {
+			return "", errors.New("auth headers must be sent as an encrypted (JWE) or signed (JWS) envelope")
+		}
+		return data, nil
+	}
+	if k == nil {
+		return "", errors.New("protected auth headers envelopes are not configured")
+	}
+	payload, err := k.open(data, true)
+	if err != nil {
+		return "", err
+	}
+	return string(payload), nil
+}
+
+func (k *EnvelopeKeys) open(data string, allowEncrypted bool) ([]byte, error) {
+	switch strings.Count(data, ".") {
+	case 4:
+		if !allowEncrypted {
+			return nil, errors.New("nested encrypted envelopes are not supported")
+		}
+		payload, err := k.decrypt(data)
+		if err != nil {
+			return nil, err
+		}
+		// sign-then-encrypt, the decrypted payload is a signed envelope
+		if nested := strings.TrimSpace(string(payload)); IsProtectedEnvelope(nested) {
+			return k.open(nested, false)
+		}
+		return payload, nil
+	case 2:
+		return k.verify(data)
+	}
+	return nil, errors.New("invalid auth headers envelope")
+}
+
+func (k *EnvelopeKeys) decrypt(data string) ([]byte, error) {
+	if len(k.decryptionKeys) == 0 {
+		return nil, errors.New("encrypted auth headers envelopes are not accepted, no decryption key configured")
+	}
+	jwe, err := jose.ParseEncryptedCompact(data, envelopeKeyAlgorithms, envelopeContentEncryptions)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encrypted auth headers envelope: %w", err)
+	}
+	for _, key := range k.decryptionKeys {
+		if key.KeyID != "" && jwe.Header.KeyID != "" && key.KeyID != jwe.Header.KeyID {
+			continue
+		}
+		if payload, err := jwe.Decrypt(key); err == nil {
+			return payload, nil
+		}
+	}
+	return nil, errors.New("failed to decrypt auth headers envelope with the configured keys")
+}
+
+func (k *EnvelopeKeys) verify(data string) ([]byte, error) {
+	if len(k.verificationKeys) == 0 {
+		return nil, errors.New("signed auth headers envelopes are not accepted, no verification key configured")
+	}
+	jws, err := jose.ParseSignedCompact(data, envelopeSignatureAlgorithms)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signed auth headers envelope: %w", err)
+	}
+	keyID := ""
+	if len(jws.Signatures) > 0 {
+		keyID = jws.Signatures[0].Header.KeyID
+	}
+	for _, key := range k.verificationKeys {
+		if key.KeyID != "" && keyID != "" && key.KeyID != keyID {
+			continue
+		}
+		if payload, err := jws.Verify(key); err == nil {
+			return payload, nil
+		}
+	}
+	return nil, errors.New("auth headers envelope signature is not from a trusted gateway")
+}
+
+// loadKeyFile reads a PEM or JWK / JWK set file. Public keys are only accepted for verification.
+func loadKeyFile(file string, public bool) ([]jose.JSONWebKey, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var keys []jose.JSONWebKey
+	if block, rest := pem.Decode(data); block != nil {
+		for block != nil {
+			key, err := parsePEMKey(block, public)
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, jose.JSONWebKey{Key: key})
+			block, rest = pem.Decode(rest)
+		}
+	} else {
+		keys, err = parseJWKs(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no keys found")
+	}
+	for i, key := range keys {
+		switch {
+		case public && !key.IsPublic() && key.Valid():
+			if _, symmetric := key.Key.([]byte); symmetric {
+				return nil, errors.New("symmetric keys cannot be used to verify signatures of a trusted gateway")
+			}
+			keys[i] = key.Public()
+		case !public && key.IsPublic():
+			return nil, errors.New("a private or symmetric key is required to decrypt envelopes")
+		}
+	}
+	return keys, nil
+}
+
+func parsePEMKey(block *pem.Block, public bool) (any, error) {
+	if public {
+		switch block.Type {
+		case "CERTIFICATE":
+			certificate, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			return certificate.PublicKey, nil
+		case "RSA PUBLIC KEY":
+			return x509.ParsePKCS1PublicKey(block.Bytes)
+		default:
+			return x509.ParsePKIXPublicKey(block.Bytes)
+		}
+	}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	default:
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+}
+
+func parseJWKs(data []byte) ([]jose.JSONWebKey, error) {
+	var keySet jose.JSONWebKeySet
+	if err := json.Unmarshal(data, &keySet); err == nil && len(keySet.Keys) > 0 {
+		return keySet.Keys, nil
+	}
+	var key jose.JSONWebKey
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, fmt.Errorf("key file is neither PEM nor JWK: %w", err)
+	}
+	return []jose.JSONWebKey{key}, nil
+}
@@ -0,0 +1,62 @@
+package kubernetes
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const bearerPrefix = "Bearer "
+
+// NewAuthDataFromHTTPHeaders builds the auth headers payload from discrete X-Target-K8s-* HTTP headers
+// and the value of the Authorization header.
+//
+// The precedence when both forms are present is:
+//  1. Discrete X-Target-K8s-* headers override the same key of a JSON envelope in the Authorization header.
+//  2. Keys only present in the JSON envelope are kept.
+//  3. If the Authorization header is a plain bearer token, it is used as x-target-k8s-authorization unless
+//     the discrete header is set.
+//  4. An encrypted, signed or multi-target envelope cannot be combined with discrete headers.
+//
+// Returns an empty string if no discrete header is present, in which case the Authorization header
+// must be used as-is.
+func NewAuthDataFromHTTPHeaders(authorization string, headers http.Header) (string, error) {
+	discrete := make(map[string]any)
+	for _, key := range AuthHeaderKeys {
+		if value := strings.TrimSpace(headers.Get(string(key))); value != "" {
+			discrete[string(key)] = value
+		}
+	}
+	if len(discrete) == 0 {
+		return "", nil
+	}
+
+	authDataMap := make(map[string]any)
+	switch {
+	case strings.HasPrefix(authorization, bearerPrefix):
+		authDataMap[string(CustomAuthorizationHeader)] = strings.TrimPrefix(authorization, bearerPrefix)
+	case IsProtectedEnvelope(authorization):
+		return "", errors.New("discrete auth headers cannot be combined with an encrypted or signed envelope")
+	case authorization != "":
+		envelope, err := DecodeAuthData(authorization)
+		if err != nil {
+			return "", fmt.Errorf("authorization header is neither a bearer token nor an auth headers payload: %w", err)
+		}
+		if _, isMultiTarget := envelope[AuthTargetsKey]; isMultiTarget {
+			return "", errors.New("discrete auth headers cannot be combined with a multi-target envelope")
+		}
+		authDataMap = envelope
+	}
+	for key, value := range discrete {
+		authDataMap[key] = value
+	}
+
+	payload, err := json.Marshal(authDataMap)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal auth data: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(payload), nil
+}
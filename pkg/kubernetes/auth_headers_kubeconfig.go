@@ -0,0 +1,103 @@
+package kubernetes
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mfaizanse/ext-kyma-mcp/pkg/config"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// NewK8sAuthHeadersFromKubeconfig maps a kubeconfig document onto K8sAuthHeaders.
+// The context is selected by contextName, or the kubeconfig current-context if empty.
+//
+// Only inline credentials are supported: exec plugins, auth providers and references to local files
+// are rejected, since they would be executed or read on the server on behalf of the caller.
+func NewK8sAuthHeadersFromKubeconfig(kubeconfig []byte, contextName string) (*K8sAuthHeaders, error) {
+	kubeConfig, err := clientcmd.Load(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse kubeconfig: %w", err)
+	}
+
+	if contextName == "" {
+		contextName = kubeConfig.CurrentContext
+	}
+	if contextName == "" {
+		return nil, fmt.Errorf("kubeconfig has no current-context, %s header is required", CustomContextHeader)
+	}
+	kubeContext, ok := kubeConfig.Contexts[contextName]
+	if !ok || kubeContext == nil {
+		return nil, fmt.Errorf("context %q not found in kubeconfig", contextName)
+	}
+	cluster, ok := kubeConfig.Clusters[kubeContext.Cluster]
+	if !ok || cluster == nil {
+		return nil, fmt.Errorf("cluster %q of context %q not found in kubeconfig", kubeContext.Cluster, contextName)
+	}
+	authInfo, ok := kubeConfig.AuthInfos[kubeContext.AuthInfo]
+	if !ok || authInfo == nil {
+		return nil, fmt.Errorf("user %q of context %q not found in kubeconfig", kubeContext.AuthInfo, contextName)
+	}
+
+	if err := validateKubeconfigCluster(cluster); err != nil {
+		return nil, fmt.Errorf("unsupported cluster %q in kubeconfig: %w", kubeContext.Cluster, err)
+	}
+	if err := validateKubeconfigAuthInfo(authInfo); err != nil {
+		return nil, fmt.Errorf("unsupported user %q in kubeconfig: %w", kubeContext.AuthInfo, err)
+	}
+
+	authHeaders := &K8sAuthHeaders{
+		Server:                   cluster.Server,
+		CertificateAuthorityData: cluster.CertificateAuthorityData,
+		InsecureSkipTLSVerify:    cluster.InsecureSkipTLSVerify,
+		TLSServerName:            cluster.TLSServerName,
+		ProxyURL:                 cluster.ProxyURL,
+		AuthorizationToken:       authInfo.Token,
+		ClientCertificateData:    authInfo.ClientCertificateData,
+		ClientKeyData:            authInfo.ClientKeyData,
+		ImpersonateUser:          authInfo.Impersonate,
+		ImpersonateGroups:        authInfo.ImpersonateGroups,
+		ImpersonateUID:           authInfo.ImpersonateUID,
+	}
+
+	if authHeaders.Server == "" {
+		return nil, fmt.Errorf("cluster %q of context %q has no server", kubeContext.Cluster, contextName)
+	}
+	if authHeaders.ProxyURL != "" {
+		if err := config.ValidateProxyURL(authHeaders.ProxyURL); err != nil {
+			return nil, fmt.Errorf("invalid proxy-url in kubeconfig: %w", err)
+		}
+	}
+	if !authHeaders.IsValid() {
+		return nil, fmt.Errorf("user %q of context %q must provide either a token or client-certificate-data and client-key-data", kubeContext.AuthInfo, contextName)
+	}
+
+	return authHeaders, nil
+}
+
+func validateKubeconfigCluster(cluster *clientcmdapi.Cluster) error {
+	if cluster.CertificateAuthority != "" {
+		return errors.New("certificate-authority file references are not supported, use certificate-authority-data")
+	}
+	return nil
+}
+
+func validateKubeconfigAuthInfo(authInfo *clientcmdapi.AuthInfo) error {
+	switch {
+	case authInfo.Exec != nil:
+		return errors.New("exec plugins are not supported")
+	case authInfo.AuthProvider != nil:
+		return errors.New("auth providers are not supported")
+	case authInfo.TokenFile != "":
+		return errors.New("tokenFile references are not supported, use token")
+	case authInfo.ClientCertificate != "" || authInfo.ClientKey != "":
+		return errors.New("client-certificate and client-key file references are not supported, use client-certificate-data and client-key-data")
+	case authInfo.Username != "" || authInfo.Password != "":
+		return errors.New("basic authentication is not supported")
+	case len(authInfo.ImpersonateUserExtra) > 0:
+		return errors.New("impersonating user extra fields is not supported")
+	case authInfo.Impersonate == "" && (len(authInfo.ImpersonateGroups) > 0 || authInfo.ImpersonateUID != ""):
+		return errors.New("as is required to impersonate groups or a UID")
+	}
+	return nil
+}
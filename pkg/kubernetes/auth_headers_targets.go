@@ -0,0 +1,124 @@
+package kubernetes
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	// AuthTargetsKey is the payload key holding the named map of target clusters of a multi-target envelope.
+	AuthTargetsKey = "targets"
+	// AuthDefaultTargetKey is the optional payload key naming the target used when the tool call does not select one.
+	AuthDefaultTargetKey = "default_target"
+)
+
+// K8sAuthTargets holds the auth headers of every target cluster described in the auth headers payload.
+//
+// A single-target payload (the x-target-k8s-* keys at the top level) is stored under the empty target name.
+// A multi-target payload has the form {"targets": {"dev": {...}, "prod": {...}}, "default_target": "dev"},
+// where every target accepts the same keys as a single-target payload.
+type K8sAuthTargets struct {
+	// Targets are the auth headers by target name.
+	Targets map[string]*K8sAuthHeaders
+	// DefaultTarget is the target used when none is selected.
+	DefaultTarget string
+}
+
+// NewK8sAuthTargetsFromString parses a single-target or multi-target auth headers payload.
+func NewK8sAuthTargetsFromString(data string) (*K8sAuthTargets, error) {
+	authDataMap, err := DecodeAuthData(data)
+	if err != nil {
+		return nil, err
+	}
+	return NewK8sAuthTargetsFromMap(authDataMap)
+}
+
+// NewK8sAuthTargetsFromMap parses a decoded single-target or multi-target auth headers payload.
+func NewK8sAuthTargetsFromMap(authDataMap map[string]any) (*K8sAuthTargets, error) {
+	targetsData, isMultiTarget := authDataMap[AuthTargetsKey]
+	if !isMultiTarget {
+		authHeaders, err := NewK8sAuthHeadersFromMap(authDataMap)
+		if err != nil {
+			return nil, err
+		}
+		return &K8sAuthTargets{Targets: map[string]*K8sAuthHeaders{"": authHeaders}}, nil
+	}
+
+	targetsMap, ok := targetsData.(map[string]any)
+	if !ok || len(targetsMap) == 0 {
+		return nil, fmt.Errorf("%s must be a non-empty map of target names to auth headers", AuthTargetsKey)
+	}
+	ret := &K8sAuthTargets{Targets: make(map[string]*K8sAuthHeaders, len(targetsMap))}
+	for name, targetData := range targetsMap {
+		if strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("%s must not contain an empty target name", AuthTargetsKey)
+		}
+		targetMap, ok := targetData.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("target %q must be a map of auth headers", name)
+		}
+		// convert keys to lower case to make header keys case-insensitive
+		targetMapLower := make(map[string]any, len(targetMap))
+		for k, v := range targetMap {
+			targetMapLower[strings.ToLower(k)] = v
+		}
+		authHeaders, err := NewK8sAuthHeadersFromMap(targetMapLower)
+		if err != nil {
+			return nil, fmt.Errorf("invalid auth headers for target %q: %w", name, err)
+		}
+		ret.Targets[name] = authHeaders
+	}
+
+	ret.DefaultTarget, _ = authDataMap[AuthDefaultTargetKey].(string)
+	if ret.DefaultTarget != "" {
+		if _, ok := ret.Targets[ret.DefaultTarget]; !ok {
+			return nil, fmt.Errorf("%s %q is not one of the targets", AuthDefaultTargetKey, ret.DefaultTarget)
+		}
+	} else if len(ret.Targets) == 1 {
+		ret.DefaultTarget = ret.Names()[0]
+	}
+	return ret, nil
+}
+
+// IsMultiTarget returns true if the payload describes named targets.
+func (t *K8sAuthTargets) IsMultiTarget() bool {
+	_, single := t.Targets[""]
+	return !single
+}
+
+// Names returns the sorted target names.
+func (t *K8sAuthTargets) Names() []string {
+	names := make([]string, 0, len(t.Targets))
+	for name := range t.Targets {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Get returns the auth headers of the target.
+//
+// The empty target selects the payload default target. The server default target (defaultTarget), which
+// the MCP server passes when the tool call does not select a target, also selects the payload default
+// target if the payload does not describe a target with that name.
+// A single-target payload is only returned for the empty or the server default target.
+func (t *K8sAuthTargets) Get(target, defaultTarget string) (*K8sAuthHeaders, error) {
+	if !t.IsMultiTarget() {
+		if target == "" || target == defaultTarget {
+			return t.Targets[""], nil
+		}
+		return nil, fmt.Errorf("target %q not found, the auth headers describe a single cluster", target)
+	}
+
+	if authHeaders, ok := t.Targets[target]; ok && target != "" {
+		return authHeaders, nil
+	}
+	if target == "" || target == defaultTarget {
+		if t.DefaultTarget == "" {
+			return nil, fmt.Errorf("no target selected and no %s in the auth headers, available targets: %s", AuthDefaultTargetKey, strings.Join(t.Names(), ", "))
+		}
+		return t.Targets[t.DefaultTarget], nil
+	}
+	return nil, fmt.Errorf("target %q not found in the auth headers, available targets: %s", target, strings.Join(t.Names(), ", "))
+}
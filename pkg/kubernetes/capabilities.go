@@ -0,0 +1,104 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	kmsapi "github.com/containers/kubernetes-mcp-server/pkg/api"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// capabilitiesTTL is how long the probed capabilities of a cluster are reused.
+	capabilitiesTTL = 5 * time.Minute
+	// capabilitiesMaxEntries is the maximum number of clusters whose capabilities are kept in memory.
+	capabilitiesMaxEntries = 1000
+
+	openShiftGroup     = "project.openshift.io"
+	openShiftRoute     = "route.openshift.io"
+	kymaOperatorGroup  = "operator.kyma-project.io"
+	istioNetworking    = "networking.istio.io"
+	istioSecurity      = "security.istio.io"
+	metricsServerGroup = "metrics.k8s.io"
+
+	gardenerShootInfoNamespace = "kube-system"
+	gardenerShootInfoName      = "shoot-info"
+)
+
+// ClusterCapabilities describes the flavour and the optional components detected in a target cluster.
+type ClusterCapabilities struct {
+	// OpenShift is true if the OpenShift project or route APIs are served.
+	OpenShift bool `json:"openShift"`
+	// Kyma is true if the Kyma operator APIs (Kyma CR, modules) are served.
+	Kyma bool `json:"kyma"`
+	// Istio is true if the Istio networking or security APIs are served.
+	Istio bool `json:"istio"`
+	// MetricsServer is true if the metrics.k8s.io API (metrics-server) is served.
+	MetricsServer bool `json:"metricsServer"`
+	// Gardener holds the shoot information if the cluster is a Gardener shoot readable by the caller.
+	Gardener *GardenerShootInfo `json:"gardener,omitempty"`
+	// ProbedAt is when the capabilities were probed.
+	ProbedAt time.Time `json:"probedAt"`
+}
+
+// GardenerShootInfo is the shoot information published by Gardener in the kube-system/shoot-info ConfigMap.
+type GardenerShootInfo struct {
+	ShootName         string   `json:"shootName,omitempty"`
+	ProjectName       string   `json:"projectName,omitempty"`
+	Provider          string   `json:"provider,omitempty"`
+	Region            string   `json:"region,omitempty"`
+	Domain            string   `json:"domain,omitempty"`
+	KubernetesVersion string   `json:"kubernetesVersion,omitempty"`
+	Extensions        []string `json:"extensions,omitempty"`
+}
+
+// capabilitiesCache caches the probed capabilities by cluster and identity.
+var capabilitiesCache = newTargetCache[*ClusterCapabilities](capabilitiesTTL, capabilitiesMaxEntries)
+
+// GetClusterCapabilities returns the capabilities of the cluster of the client.
+// The capabilities are probed with discovery on first use and cached per target cluster and identity.
+func GetClusterCapabilities(ctx context.Context, client kmsapi.KubernetesClient) (*ClusterCapabilities, error) {
+	return capabilitiesCache.getOrResolve(targetKey(client.RESTConfig()), func() (*ClusterCapabilities, error) {
+		return probeClusterCapabilities(ctx, client)
+	})
+}
+
+func probeClusterCapabilities(ctx context.Context, client kmsapi.KubernetesClient) (*ClusterCapabilities, error) {
+	groups, err := client.DiscoveryClient().ServerGroups()
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover API groups: %w", err)
+	}
+	served := make(map[string]bool, len(groups.Groups))
+	for _, group := range groups.Groups {
+		served[group.Name] = len(group.Versions) > 0
+	}
+
+	ret := &ClusterCapabilities{
+		OpenShift:     served[openShiftGroup] || served[openShiftRoute],
+		Kyma:          served[kymaOperatorGroup],
+		Istio:         served[istioNetworking] || served[istioSecurity],
+		MetricsServer: served[metricsServerGroup],
+		ProbedAt:      time.Now(),
+	}
+
+	shootInfo, err := client.CoreV1().ConfigMaps(gardenerShootInfoNamespace).Get(ctx, gardenerShootInfoName, metav1.GetOptions{})
+	if err != nil {
+		klog.V(4).Infof("Gardener shoot info not available: %v", err)
+	} else {
+		ret.Gardener = &GardenerShootInfo{
+			ShootName:         shootInfo.Data["shootName"],
+			ProjectName:       shootInfo.Data["projectName"],
+			Provider:          shootInfo.Data["provider"],
+			Region:            shootInfo.Data["region"],
+			Domain:            shootInfo.Data["domain"],
+			KubernetesVersion: shootInfo.Data["kubernetesVersion"],
+		}
+		if extensions := strings.TrimSpace(shootInfo.Data["extensions"]); extensions != "" {
+			ret.Gardener.Extensions = strings.Split(extensions, ",")
+		}
+	}
+	return ret, nil
+}
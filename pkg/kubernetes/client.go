@@ -1,14 +1,23 @@
 package kubernetes
 
 import (
+	"fmt"
+	"net/http"
+	"net/url"
+
 	kmsapi "github.com/containers/kubernetes-mcp-server/pkg/api"
 	kmskubernetes "github.com/containers/kubernetes-mcp-server/pkg/kubernetes"
+	"github.com/mfaizanse/ext-kyma-mcp/pkg/config"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 )
 
-func NewKubernetes(authHeaders *K8sAuthHeaders, config kmsapi.BaseConfig) (*kmskubernetes.Kubernetes, error) {
+// NewKubernetes creates a Kubernetes client for the cluster described by the auth headers.
+// If policy is not nil, every connection is checked against it when dialing.
+// The proxy, rate limits, timeout and user agent default to the auth-headers provider configuration.
+func NewKubernetes(authHeaders *K8sAuthHeaders, baseConfig kmsapi.BaseConfig, policy *ServerPolicy) (*kmskubernetes.Kubernetes, error) {
+	providerConfig := config.GetAuthHeadersProviderConfig(baseConfig)
 
 	var certData []byte = nil
 	if len(authHeaders.ClientCertificateData) > 0 {
@@ -24,23 +33,59 @@ func NewKubernetes(authHeaders *K8sAuthHeaders, config kmsapi.BaseConfig) (*kmsk
 		Host:        authHeaders.Server,
 		BearerToken: authHeaders.AuthorizationToken,
 		TLSClientConfig: rest.TLSClientConfig{
-			Insecure: authHeaders.InsecureSkipTLSVerify,
-			CAData:   authHeaders.CertificateAuthorityData,
-			CertData: certData,
-			KeyData:  keyData,
+			Insecure:   authHeaders.InsecureSkipTLSVerify,
+			ServerName: authHeaders.TLSServerName,
+			CAData:     authHeaders.CertificateAuthorityData,
+			CertData:   certData,
+			KeyData:    keyData,
+		},
+		Impersonate: rest.ImpersonationConfig{
+			UserName: authHeaders.ImpersonateUser,
+			UID:      authHeaders.ImpersonateUID,
+			Groups:   authHeaders.ImpersonateGroups,
 		},
+		QPS:       providerConfig.GetQPS(),
+		Burst:     providerConfig.GetBurst(),
+		Timeout:   providerConfig.GetTimeout(),
+		UserAgent: providerConfig.GetUserAgent(),
+	}
+	if policy != nil {
+		restConfig.Dial = policy.DialContext
+	}
+
+	// A per-request proxy takes precedence over the proxy configured on the server.
+	proxyURL := authHeaders.ProxyURL
+	trustedProxy := false
+	if proxyURL == "" && providerConfig.ProxyURL != "" {
+		proxyURL = providerConfig.ProxyURL
+		trustedProxy = true
+	}
+	if proxyURL != "" {
+		u, err := url.Parse(proxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		restConfig.Proxy = http.ProxyURL(u)
+		if policy != nil && trustedProxy {
+			restConfig.Dial = policy.DialContextWithTrustedProxy(u)
+		}
 	}
 	// Create a dummy kubeconfig clientcmdapi.Config to be used in places where clientcmd.ClientConfig is required.
 	clientCmdConfig := clientcmdapi.NewConfig()
 	clientCmdConfig.Clusters["cluster"] = &clientcmdapi.Cluster{
 		Server:                authHeaders.Server,
 		InsecureSkipTLSVerify: authHeaders.InsecureSkipTLSVerify,
+		TLSServerName:         authHeaders.TLSServerName,
+		ProxyURL:              proxyURL,
 	}
 	clientCmdConfig.AuthInfos["user"] = &clientcmdapi.AuthInfo{
 		Token:                 authHeaders.AuthorizationToken,
 		ClientCertificateData: certData,
 		ClientKeyData:         keyData,
+		Impersonate:           authHeaders.ImpersonateUser,
+		ImpersonateUID:        authHeaders.ImpersonateUID,
+		ImpersonateGroups:     authHeaders.ImpersonateGroups,
 	}
 
-	return kmskubernetes.NewKubernetes(config, clientcmd.NewDefaultClientConfig(*clientCmdConfig, nil), restConfig)
+	return kmskubernetes.NewKubernetes(baseConfig, clientcmd.NewDefaultClientConfig(*clientCmdConfig, nil), restConfig)
 }
@@ -0,0 +1,218 @@
+package kubernetes
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	kmskubernetes "github.com/containers/kubernetes-mcp-server/pkg/kubernetes"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	"golang.org/x/sync/singleflight"
+	"k8s.io/klog/v2"
+)
+
+const clientCacheMeterName = "ext-kyma-mcp/kubernetes"
+
+// ClientCacheStats is a snapshot of the derived client cache counters.
+type ClientCacheStats struct {
+	Hits      int64
+	Misses    int64
+	Evictions int64
+	Size      int
+}
+
+type clientCacheEntry struct {
+	client    *kmskubernetes.Kubernetes
+	expiresAt time.Time
+	lastUsed  time.Time
+}
+
+// clientCache is a bounded, TTL-based cache of derived Kubernetes clients.
+// Entries are keyed by a SHA-256 fingerprint of the auth headers, so credentials are never kept as cleartext keys.
+type clientCache struct {
+	mu         sync.Mutex
+	entries    map[string]*clientCacheEntry
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+	// inflight deduplicates the concurrent creations of the same client
+	inflight singleflight.Group
+
+	hits      atomic.Int64
+	misses    atomic.Int64
+	evictions atomic.Int64
+
+	hitCounter      metric.Int64Counter
+	missCounter     metric.Int64Counter
+	evictionCounter metric.Int64Counter
+}
+
+func newClientCache(ttl time.Duration, maxEntries int) *clientCache {
+	c := &clientCache{
+		entries:    make(map[string]*clientCacheEntry),
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		now:        time.Now,
+	}
+	meter := otel.Meter(clientCacheMeterName)
+	c.hitCounter, _ = meter.Int64Counter("auth_headers.client_cache.hits",
+		metric.WithDescription("Number of derived Kubernetes clients served from the cache"))
+	c.missCounter, _ = meter.Int64Counter("auth_headers.client_cache.misses",
+		metric.WithDescription("Number of derived Kubernetes clients that had to be created"))
+	c.evictionCounter, _ = meter.Int64Counter("auth_headers.client_cache.evictions",
+		metric.WithDescription("Number of derived Kubernetes clients evicted from the cache"))
+	return c
+}
+
+// enabled reports whether caching is active. A zero TTL disables the cache.
+func (c *clientCache) enabled() bool {
+	return c != nil && c.ttl > 0 && c.maxEntries > 0
+}
+
+// getOrCreate returns the cached client for the key or creates (and caches) a new one with create.
+// Concurrent misses on the same key share a single creation.
+func (c *clientCache) getOrCreate(ctx context.Context, key string, create func() (*kmskubernetes.Kubernetes, error)) (*kmskubernetes.Kubernetes, error) {
+	if !c.enabled() {
+		return create()
+	}
+
+	if client, ok := c.get(ctx, key); ok {
+		return client, nil
+	}
+
+	client, err, _ := c.inflight.Do(key, func() (any, error) {
+		// the client may have been stored by a creation that completed while this one was waiting
+		if client, ok := c.get(ctx, key); ok {
+			return client, nil
+		}
+
+		c.misses.Add(1)
+		c.missCounter.Add(ctx, 1)
+		client, err := create()
+		if err != nil {
+			return nil, err
+		}
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		now := c.now()
+		c.evictExpiredLocked(ctx, now)
+		if _, exists := c.entries[key]; !exists {
+			for len(c.entries) >= c.maxEntries {
+				c.evictOldestLocked(ctx)
+			}
+		}
+		c.entries[key] = &clientCacheEntry{
+			client:    client,
+			expiresAt: now.Add(c.ttl),
+			lastUsed:  now,
+		}
+		klog.V(4).Infof("auth-headers client cache: stored new client (size=%d, hits=%d, misses=%d)", len(c.entries), c.hits.Load(), c.misses.Load())
+		return client, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return client.(*kmskubernetes.Kubernetes), nil
+}
+
+// get returns the cached client for the key if it has not expired, expired entries are removed.
+func (c *clientCache) get(ctx context.Context, key string) (*kmskubernetes.Kubernetes, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	now := c.now()
+	if !now.Before(entry.expiresAt) {
+		c.removeLocked(ctx, key)
+		return nil, false
+	}
+	entry.lastUsed = now
+	c.hits.Add(1)
+	c.hitCounter.Add(ctx, 1)
+	return entry.client, true
+}
+
+func (c *clientCache) evictExpiredLocked(ctx context.Context, now time.Time) {
+	for key, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			c.removeLocked(ctx, key)
+		}
+	}
+}
+
+func (c *clientCache) evictOldestLocked(ctx context.Context) {
+	var oldestKey string
+	var oldest time.Time
+	for key, entry := range c.entries {
+		if oldestKey == "" || entry.lastUsed.Before(oldest) {
+			oldestKey = key
+			oldest = entry.lastUsed
+		}
+	}
+	if oldestKey != "" {
+		c.removeLocked(ctx, oldestKey)
+	}
+}
+
+func (c *clientCache) removeLocked(ctx context.Context, key string) {
+	delete(c.entries, key)
+	c.evictions.Add(1)
+	c.evictionCounter.Add(ctx, 1)
+}
+
+// clear drops all cached clients.
+func (c *clientCache) clear() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]*clientCacheEntry)
+}
+
+// Stats returns a snapshot of the cache counters.
+func (c *clientCache) Stats() ClientCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return ClientCacheStats{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+		Size:      len(c.entries),
+	}
+}
+
+// CacheKey returns a fingerprint identifying the cluster and credentials of the auth headers.
+// The key is a SHA-256 digest, so no credential is ever stored in cleartext.
+func (h *K8sAuthHeaders) CacheKey() string {
+	digest := sha256.New()
+	writeField(digest, []byte(h.Server))
+	writeField(digest, h.CertificateAuthorityData)
+	writeField(digest, []byte(h.AuthorizationToken))
+	writeField(digest, h.ClientCertificateData)
+	writeField(digest, h.ClientKeyData)
+	writeField(digest, []byte(strconv.FormatBool(h.InsecureSkipTLSVerify)))
+	writeField(digest, []byte(h.TLSServerName))
+	writeField(digest, []byte(h.ProxyURL))
+	writeField(digest, []byte(h.ImpersonateUser))
+	writeField(digest, []byte(strings.Join(h.ImpersonateGroups, "\n")))
+	writeField(digest, []byte(h.ImpersonateUID))
+	return hex.EncodeToString(digest.Sum(nil))
+}
+
+// writeField writes a length-prefixed field so that adjacent fields cannot collide.
+func writeField(digest hash.Hash, value []byte) {
+	_, _ = digest.Write([]byte(strconv.Itoa(len(value))))
+	_, _ = digest.Write([]byte{':'})
+	_, _ = digest.Write(value)
+}
@@ -0,0 +1,192 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	kmsapi "github.com/containers/kubernetes-mcp-server/pkg/api"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// DefaultKymaNamespace and DefaultKymaName locate the Kyma CR of a managed Kyma runtime.
+	DefaultKymaNamespace = "kyma-system"
+	DefaultKymaName      = "default"
+
+	kymaKind    = "Kyma"
+	kymaCRDName = "kymas." + kymaOperatorGroup
+
+	// kymaCRTTL is how long the resolved Kyma CR of a cluster is reused.
+	kymaCRTTL = 5 * time.Minute
+	// kymaCRMaxEntries is the maximum number of clusters whose Kyma CR is kept in memory.
+	kymaCRMaxEntries = 1000
+)
+
+var crdResource = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+
+// KymaCR locates the Kyma CR of a cluster.
+type KymaCR struct {
+	// GroupVersionKind is the Kyma kind in the storage version, or the preferred version if the CRD is not readable.
+	GroupVersionKind schema.GroupVersionKind
+	// Resource is the Kyma resource in the same version.
+	Resource  schema.GroupVersionResource
+	Namespace string
+	Name      string
+}
+
+// kymaCRCache caches the resolved Kyma CR by cluster and identity.
+var kymaCRCache = newTargetCache[*KymaCR](kymaCRTTL, kymaCRMaxEntries)
+
+// kymaResourceCache caches the resolved Kyma resource by cluster and identity, for callers locating the CR themselves.
+var kymaResourceCache = newTargetCache[schema.GroupVersionResource](kymaCRTTL, kymaCRMaxEntries)
+
+// GetKymaCR returns the Kyma CR of the cluster of the client.
+// The Kyma API version is resolved through discovery and the CRD, the Kyma CR is kyma-system/default or, if absent
+// (e.g. on lifecycle manager clusters or renamed setups), the first Kyma CR found across namespaces.
+// The result is cached per target cluster and identity.
+func GetKymaCR(ctx context.Context, client kmsapi.KubernetesClient) (*KymaCR, error) {
+	return kymaCRCache.getOrResolve(targetKey(client.RESTConfig()), func() (*KymaCR, error) {
+		return resolveKymaCR(ctx, client)
+	})
+}
+
+// GetKymaGroupVersionKind returns the Kyma kind in the version resolved as in GetKymaCR, without locating the Kyma CR.
+// Use it when the namespace and the name of the Kyma CR are known, since locating the CR may require listing the
+// Kyma CRs across namespaces.
+func GetKymaGroupVersionKind(ctx context.Context, client kmsapi.KubernetesClient) (schema.GroupVersionKind, error) {
+	resource, err := getKymaResource(ctx, client)
+	if err != nil {
+		return schema.GroupVersionKind{}, err
+	}
+	return resource.GroupVersion().WithKind(kymaKind), nil
+}
+
+func getKymaResource(ctx context.Context, client kmsapi.KubernetesClient) (schema.GroupVersionResource, error) {
+	return kymaResourceCache.getOrResolve(targetKey(client.RESTConfig()), func() (schema.GroupVersionResource, error) {
+		return resolveKymaResource(ctx, client)
+	})
+}
+
+func resolveKymaCR(ctx context.Context, client kmsapi.KubernetesClient) (*KymaCR, error) {
+	resource, err := getKymaResource(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	ret := &KymaCR{
+		GroupVersionKind: resource.GroupVersion().WithKind(kymaKind),
+		Resource:         resource,
+		Namespace:        DefaultKymaNamespace,
+		Name:             DefaultKymaName,
+	}
+
+	_, err = client.DynamicClient().Resource(resource).Namespace(DefaultKymaNamespace).Get(ctx, DefaultKymaName, metav1.GetOptions{})
+	if err == nil {
+		return ret, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to get the Kyma CR %s/%s: %w", DefaultKymaNamespace, DefaultKymaName, err)
+	}
+	list, err := client.DynamicClient().Resource(resource).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("the Kyma CR %s/%s does not exist and the Kyma CRs cannot be listed: %w", DefaultKymaNamespace, DefaultKymaName, err)
+	}
+	if len(list.Items) == 0 {
+		return nil, fmt.Errorf("no Kyma CR found in the cluster")
+	}
+	// Kyma CRs of kyma-system first, then by namespace and name
+	slices.SortFunc(list.Items, func(a, b unstructured.Unstructured) int {
+		if (a.GetNamespace() == DefaultKymaNamespace) != (b.GetNamespace() == DefaultKymaNamespace) {
+			if a.GetNamespace() == DefaultKymaNamespace {
+				return -1
+			}
+			return 1
+		}
+		if c := strings.Compare(a.GetNamespace(), b.GetNamespace()); c != 0 {
+			return c
+		}
+		return strings.Compare(a.GetName(), b.GetName())
+	})
+	if len(list.Items) > 1 {
+		klog.V(2).Infof("%d Kyma CRs found, using %s/%s", len(list.Items), list.Items[0].GetNamespace(), list.Items[0].GetName())
+	}
+	ret.Namespace = list.Items[0].GetNamespace()
+	ret.Name = list.Items[0].GetName()
+	return ret, nil
+}
+
+// resolveKymaResource resolves the Kyma resource in the storage version of the CRD, if served, or else in the
+// preferred version of the Kyma API group.
+func resolveKymaResource(ctx context.Context, client kmsapi.KubernetesClient) (schema.GroupVersionResource, error) {
+	groups, err := client.DiscoveryClient().ServerGroups()
+	if err != nil {
+		return schema.GroupVersionResource{}, fmt.Errorf("failed to discover API groups: %w", err)
+	}
+	idx := slices.IndexFunc(groups.Groups, func(group metav1.APIGroup) bool { return group.Name == kymaOperatorGroup })
+	if idx < 0 {
+		return schema.GroupVersionResource{}, fmt.Errorf("the Kyma API %s is not served, is Kyma installed?", kymaOperatorGroup)
+	}
+	group := groups.Groups[idx]
+
+	storageVersion, err := kymaStorageVersion(ctx, client)
+	if err != nil {
+		klog.V(4).Infof("Kyma CRD not readable, using the preferred version: %v", err)
+	}
+	// the storage version first, then the preferred version, then the other served versions
+	candidates := []string{storageVersion, group.PreferredVersion.Version}
+	for _, version := range group.Versions {
+		candidates = append(candidates, version.Version)
+	}
+	versions := make([]string, 0, len(candidates))
+	for _, version := range candidates {
+		served := slices.ContainsFunc(group.Versions, func(v metav1.GroupVersionForDiscovery) bool { return v.Version == version })
+		if served && !slices.Contains(versions, version) {
+			versions = append(versions, version)
+		}
+	}
+
+	for _, version := range versions {
+		gv := schema.GroupVersion{Group: kymaOperatorGroup, Version: version}
+		resources, err := client.DiscoveryClient().ServerResourcesForGroupVersion(gv.String())
+		if err != nil {
+			klog.V(4).Infof("failed to discover the resources of %s: %v", gv, err)
+			continue
+		}
+		for _, resource := range resources.APIResources {
+			if resource.Kind == kymaKind && !strings.Contains(resource.Name, "/") {
+				return gv.WithResource(resource.Name), nil
+			}
+		}
+	}
+	return schema.GroupVersionResource{}, fmt.Errorf("the %s kind is not served by the Kyma API %s", kymaKind, kymaOperatorGroup)
+}
+
+// kymaStorageVersion returns the served storage version of the Kyma CRD.
+func kymaStorageVersion(ctx context.Context, client kmsapi.KubernetesClient) (string, error) {
+	crd, err := client.DynamicClient().Resource(crdResource).Get(ctx, kymaCRDName, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	for _, version := range versions {
+		versionMap, ok := version.(map[string]any)
+		if !ok {
+			continue
+		}
+		if storage, _ := versionMap["storage"].(bool); !storage {
+			continue
+		}
+		if served, _ := versionMap["served"].(bool); !served {
+			return "", fmt.Errorf("the storage version of %s is not served", kymaCRDName)
+		}
+		name, _ := versionMap["name"].(string)
+		return name, nil
+	}
+	return "", fmt.Errorf("no storage version in %s", kymaCRDName)
+}
@@ -4,11 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"sync"
 
 	kmsapi "github.com/containers/kubernetes-mcp-server/pkg/api"
 	kmskubernetes "github.com/containers/kubernetes-mcp-server/pkg/kubernetes"
 	"github.com/mfaizanse/ext-kyma-mcp/pkg/config"
 	authenticationv1api "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 )
 
@@ -17,10 +20,40 @@ import (
 // It uses cluster connection details from configuration but does not use any
 // authentication credentials from kubeconfig files.
 type AuthHeadersClusterProvider struct {
-	config kmsapi.BaseConfig
+	mu             sync.RWMutex
+	config         kmsapi.BaseConfig
+	clientCache    *clientCache
+	serverPolicy   *ServerPolicy
+	envelopeKeys   *EnvelopeKeys
+	tokenExchanger *tokenExchanger
+	subjects       *verifiedSubjects
+	verifications  *targetCache[*tokenVerification]
+	// rejectionHandler reports the rejected auth headers for the audit log, see SetRejectionHandler.
+	rejectionHandler func(ctx context.Context, rejection *Rejection)
+}
+
+// TokenVerifier is implemented by providers that can verify bearer tokens against the target cluster.
+type TokenVerifier interface {
+	VerifyToken(ctx context.Context, target, token, audience string) (*authenticationv1api.UserInfo, []string, error)
+}
+
+// AuthHeadersTargetParameterName is the tool parameter selecting the target of a multi-target envelope.
+const AuthHeadersTargetParameterName = "target"
+
+// AuthHeadersParser is implemented by providers that can open the (possibly protected) auth headers envelope.
+type AuthHeadersParser interface {
+	ParseAuthTargets(data string) (*K8sAuthTargets, error)
+}
+
+// ConfigReloader is implemented by providers that can apply a reloaded configuration (e.g. on SIGHUP).
+type ConfigReloader interface {
+	ReloadConfig(cfg kmsapi.BaseConfig) error
 }
 
 var _ kmskubernetes.Provider = &AuthHeadersClusterProvider{}
+var _ TokenVerifier = &AuthHeadersClusterProvider{}
+var _ AuthHeadersParser = &AuthHeadersClusterProvider{}
+var _ ConfigReloader = &AuthHeadersClusterProvider{}
 
 func init() {
 	kmskubernetes.RegisterProvider(config.ClusterProviderAuthHeaders, newAuthHeadersClusterProvider)
@@ -29,59 +62,280 @@ func init() {
 // newAuthHeadersClusterProvider creates a provider that requires header-based authentication.
 // Users must provide tokens via request headers (server URL, Token or client certificate and key).
 func newAuthHeadersClusterProvider(cfg kmsapi.BaseConfig) (kmskubernetes.Provider, error) {
-	ret := &AuthHeadersClusterProvider{config: cfg}
-	if err := ret.reset(); err != nil {
+	ret := &AuthHeadersClusterProvider{}
+	if err := ret.reset(cfg); err != nil {
 		return nil, err
 	}
 	return ret, nil
 }
 
 func (p *AuthHeadersClusterProvider) GetDerivedKubernetes(ctx context.Context, target string) (*kmskubernetes.Kubernetes, error) {
+	return p.getDerivedKubernetes(ctx, target, true)
+}
+
+// getDerivedKubernetes returns the client for the target of the auth headers in the context.
+// If impersonate is false, the impersonation requested in the auth headers is ignored, so that
+// the client acts with the identity of the provided credentials.
+func (p *AuthHeadersClusterProvider) getDerivedKubernetes(ctx context.Context, target string, impersonate bool) (*kmskubernetes.Kubernetes, error) {
 	authData, ok := ctx.Value(kmskubernetes.OAuthAuthorizationHeader).(string)
 	if !ok {
-		return nil, errors.New("authHeaders required")
+		return nil, p.reject(ctx, target, nil, errors.New("authHeaders required"))
 	}
 
-	authHeaders, err := NewK8sAuthHeadersFromString(authData)
+	authTargets, err := p.ParseAuthTargets(authData)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse auth headers: %w", err)
+		return nil, p.reject(ctx, target, nil, fmt.Errorf("failed to parse auth headers: %w", err))
 	}
+	authHeaders, err := authTargets.Get(target, p.GetDefaultTarget())
+	if err != nil {
+		return nil, p.reject(ctx, target, nil, err)
+	}
+	if !impersonate && authHeaders.IsImpersonating() {
+		withoutImpersonation := *authHeaders
+		withoutImpersonation.ImpersonateUser, withoutImpersonation.ImpersonateGroups, withoutImpersonation.ImpersonateUID = "", nil, ""
+		authHeaders = &withoutImpersonation
+	}
+
+	p.mu.RLock()
+	cfg, clientCache, serverPolicy, tokenExchanger := p.config, p.clientCache, p.serverPolicy, p.tokenExchanger
+	p.mu.RUnlock()
 
-	return NewKubernetes(authHeaders, p.config)
+	providerConfig := config.GetAuthHeadersProviderConfig(cfg)
+	if tokenExchanger != nil {
+		// the exchange connects to the target server (use_cluster_issuer) and sends the caller token to the IdP,
+		// so the auth headers are checked before, not only when the client is created
+		if err := checkAuthHeaders(ctx, providerConfig, serverPolicy, authHeaders); err != nil {
+			return nil, p.reject(ctx, target, authHeaders, err)
+		}
+		exchanged, err := tokenExchanger.exchange(ctx, authHeaders)
+		if err != nil {
+			return nil, p.reject(ctx, target, authHeaders, err)
+		}
+		authHeaders = exchanged
+	}
+
+	return clientCache.getOrCreate(ctx, authHeaders.CacheKey(), func() (*kmskubernetes.Kubernetes, error) {
+		if err := checkAuthHeaders(ctx, providerConfig, serverPolicy, authHeaders); err != nil {
+			return nil, p.reject(ctx, target, authHeaders, err)
+		}
+		return NewKubernetes(authHeaders, cfg, serverPolicy)
+	})
 }
 
+// checkAuthHeaders validates the target server, the TLS verification, the proxy and the impersonation of the
+// auth headers against the server policy and the configuration.
+func checkAuthHeaders(ctx context.Context, providerConfig *config.AuthHeadersProviderConfig, serverPolicy *ServerPolicy, authHeaders *K8sAuthHeaders) error {
+	if err := serverPolicy.CheckServer(ctx, authHeaders.Server); err != nil {
+		return err
+	}
+	if authHeaders.InsecureSkipTLSVerify {
+		if err := serverPolicy.CheckInsecureSkipTLSVerify(authHeaders.Server); err != nil {
+			return err
+		}
+	}
+	if authHeaders.ProxyURL != "" {
+		if err := serverPolicy.CheckProxyURL(ctx, authHeaders.ProxyURL); err != nil {
+			return err
+		}
+	}
+	return checkImpersonation(providerConfig, authHeaders)
+}
+
+// checkImpersonation validates the impersonation requested in the auth headers against the configuration.
+func checkImpersonation(providerConfig *config.AuthHeadersProviderConfig, authHeaders *K8sAuthHeaders) error {
+	if !authHeaders.IsImpersonating() {
+		return nil
+	}
+	if !providerConfig.ImpersonationEnabled {
+		return fmt.Errorf("impersonation is disabled by the server configuration, remove %s", CustomImpersonateUserHeader)
+	}
+	for _, group := range authHeaders.ImpersonateGroups {
+		if !providerConfig.IsImpersonationGroupAllowed(group) {
+			return fmt.Errorf("impersonating group %q is not allowed by the server configuration", group)
+		}
+	}
+	return nil
+}
+
+// ParseAuthTargets opens the auth headers envelope with the configured envelope keys and parses the payload.
+func (p *AuthHeadersClusterProvider) ParseAuthTargets(data string) (*K8sAuthTargets, error) {
+	p.mu.RLock()
+	envelopeKeys := p.envelopeKeys
+	p.mu.RUnlock()
+
+	payload, err := envelopeKeys.Open(data)
+	if err != nil {
+		return nil, err
+	}
+	return NewK8sAuthTargetsFromString(payload)
+}
+
+// ClientCacheStats returns a snapshot of the derived client cache counters.
+func (p *AuthHeadersClusterProvider) ClientCacheStats() ClientCacheStats {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.clientCache.Stats()
+}
+
+// IsOpenShift detects OpenShift with the (cached) capability probe of the default target of the context.
+// Without auth headers in the context (e.g. when the tools are registered), false is returned.
 func (p *AuthHeadersClusterProvider) IsOpenShift(ctx context.Context) bool {
-	klog.V(1).Infof("IsOpenShift not supported for auth-headers provider. Returning false.")
-	return false
+	if _, ok := ctx.Value(kmskubernetes.OAuthAuthorizationHeader).(string); !ok {
+		return false
+	}
+	k, err := p.GetDerivedKubernetes(ctx, p.GetDefaultTarget())
+	if err != nil {
+		klog.V(2).Infof("failed to detect OpenShift: %v", err)
+		return false
+	}
+	capabilities, err := GetClusterCapabilities(ctx, k)
+	if err != nil {
+		klog.V(2).Infof("failed to detect OpenShift: %v", err)
+		return false
+	}
+	return capabilities.OpenShift
 }
 
+// VerifyToken verifies the bearer token against the cluster described in the auth headers of the context.
+// Depending on the configured token review mode, a TokenReview or a SelfSubjectReview is performed.
 func (p *AuthHeadersClusterProvider) VerifyToken(ctx context.Context, target, token, audience string) (*authenticationv1api.UserInfo, []string, error) {
-	return nil, nil, fmt.Errorf("VerifyToken not supported for auth-headers provider")
+	if token == "" {
+		return nil, nil, errors.New("token is required")
+	}
+	// The token is verified with its own identity, since the impersonated user may not review tokens
+	k, err := p.getDerivedKubernetes(ctx, target, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p.mu.RLock()
+	cfg, tokenExchanger, subjects, verifications := p.config, p.tokenExchanger, p.subjects, p.verifications
+	p.mu.RUnlock()
+	// The verified subject is remembered by the provided token for the audit log
+	server, providedToken := k.RESTConfig().Host, token
+	// The exchanged token is the one used against the cluster
+	if tokenExchanger != nil {
+		token = k.RESTConfig().BearerToken
+	}
+	mode := config.GetAuthHeadersProviderConfig(cfg).GetTokenReviewMode()
+	verification, err := verifications.getOrResolve(tokenVerificationKey(server, providedToken, audience, mode), func() (*tokenVerification, error) {
+		return reviewToken(ctx, k, mode, token, audience)
+	})
+	if err != nil {
+		return nil, nil, p.reject(ctx, target, &K8sAuthHeaders{Server: server}, err)
+	}
+	subjects.store(server, providedToken, verification.userInfo.Username)
+	return verification.userInfo, verification.audiences, nil
+}
+
+// reviewToken verifies the token with a SelfSubjectReview or a TokenReview, depending on the mode.
+func reviewToken(ctx context.Context, k *kmskubernetes.Kubernetes, mode, token, audience string) (*tokenVerification, error) {
+	if mode == config.TokenReviewModeSelfSubjectReview {
+		if k.RESTConfig().BearerToken != token {
+			return nil, errors.New("self-subject-review can only verify the token provided in the auth headers")
+		}
+		if audience != "" {
+			return nil, fmt.Errorf("audience %q cannot be validated with %s, use %s", audience, config.TokenReviewModeSelfSubjectReview, config.TokenReviewModeTokenReview)
+		}
+		review, err := k.AuthenticationV1().SelfSubjectReviews().Create(ctx, &authenticationv1api.SelfSubjectReview{}, metav1.CreateOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create self subject review: %w", err)
+		}
+		return &tokenVerification{userInfo: &review.Status.UserInfo}, nil
+	}
+
+	tokenReview := &authenticationv1api.TokenReview{
+		Spec: authenticationv1api.TokenReviewSpec{Token: token},
+	}
+	if audience != "" {
+		tokenReview.Spec.Audiences = []string{audience}
+	}
+	review, err := k.AuthenticationV1().TokenReviews().Create(ctx, tokenReview, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token review: %w", err)
+	}
+	if !review.Status.Authenticated {
+		if review.Status.Error != "" {
+			return nil, fmt.Errorf("token is not authenticated: %s", review.Status.Error)
+		}
+		return nil, errors.New("token is not authenticated")
+	}
+	return &tokenVerification{userInfo: &review.Status.User, audiences: review.Status.Audiences}, nil
 }
 
-func (p *AuthHeadersClusterProvider) GetTargets(_ context.Context) ([]string, error) {
-	klog.V(1).Infof("GetTargets not supported for auth-headers provider. Returning empty list.")
+// GetTargets returns the targets of the multi-target envelope in the context, if any.
+// Otherwise (e.g. when the tools are registered), the configured targets are returned.
+func (p *AuthHeadersClusterProvider) GetTargets(ctx context.Context) ([]string, error) {
+	if authData, ok := ctx.Value(kmskubernetes.OAuthAuthorizationHeader).(string); ok {
+		authTargets, err := p.ParseAuthTargets(authData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse auth headers: %w", err)
+		}
+		if authTargets.IsMultiTarget() {
+			return authTargets.Names(), nil
+		}
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if targets := config.GetAuthHeadersProviderConfig(p.config).Targets; len(targets) > 0 {
+		return slices.Clone(targets), nil
+	}
 	return []string{""}, nil
 }
 
 func (p *AuthHeadersClusterProvider) GetTargetParameterName() string {
-	klog.V(1).Infof("GetTargetParameterName not supported for auth-headers provider. Returning empty name.")
-	return ""
+	return AuthHeadersTargetParameterName
 }
 
+// GetDefaultTarget returns the configured default target.
+// When empty, the default target of the envelope is used.
 func (p *AuthHeadersClusterProvider) GetDefaultTarget() string {
-	klog.V(1).Infof("GetDefaultTarget not supported for auth-headers provider. Returning empty name.")
-	return ""
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return config.GetAuthHeadersProviderConfig(p.config).GetDefaultTarget()
 }
 
 func (p *AuthHeadersClusterProvider) WatchTargets(reload kmskubernetes.McpReload) {
 	klog.V(1).Infof("WatchTargets not supported for auth-headers provider. Ignoring watch function.")
 }
 
-func (p *AuthHeadersClusterProvider) reset() error {
-	klog.V(1).Infof("reset not supported for auth-headers provider. Ignoring reset function.")
+// ReloadConfig applies the reloaded configuration, re-reading the envelope key files.
+// On error, the previous configuration and keys are kept.
+func (p *AuthHeadersClusterProvider) ReloadConfig(cfg kmsapi.BaseConfig) error {
+	return p.reset(cfg)
+}
+
+func (p *AuthHeadersClusterProvider) reset(cfg kmsapi.BaseConfig) error {
+	providerConfig := config.GetAuthHeadersProviderConfig(cfg)
+	envelopeKeys, err := LoadEnvelopeKeys(providerConfig)
+	if err != nil {
+		return err
+	}
+	serverPolicy := NewServerPolicy(providerConfig)
+	tokenExchanger, err := newTokenExchanger(providerConfig.TokenExchange, serverPolicy)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clientCache.clear()
+	p.config = cfg
+	p.clientCache = newClientCache(providerConfig.GetClientCacheTTL(), providerConfig.GetClientCacheMaxEntries())
+	p.serverPolicy = serverPolicy
+	p.envelopeKeys = envelopeKeys
+	p.tokenExchanger = tokenExchanger
+	p.subjects = newVerifiedSubjects()
+	p.verifications = newTargetCache[*tokenVerification](tokenVerificationsTTL, tokenVerificationsMaxEntries)
+	klog.V(1).Infof("auth-headers provider client cache: ttl=%s, max entries=%d", providerConfig.GetClientCacheTTL(), providerConfig.GetClientCacheMaxEntries())
+	klog.V(1).Infof("auth-headers provider transport: proxy=%t, qps=%v, burst=%d, timeout=%s", providerConfig.ProxyURL != "", providerConfig.GetQPS(), providerConfig.GetBurst(), providerConfig.GetTimeout())
+	klog.V(1).Infof("auth-headers provider envelope keys: decryption=%d, verification=%d, required=%t", len(envelopeKeys.decryptionKeys), len(envelopeKeys.verificationKeys), envelopeKeys.required)
 	return nil
 }
 
 func (p *AuthHeadersClusterProvider) Close() {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	p.clientCache.clear()
 }
@@ -0,0 +1,335 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mfaizanse/ext-kyma-mcp/pkg/config"
+)
+
+var (
+	// metadataPrefixes are cloud metadata endpoints, always rejected unless explicitly allowed by CIDR.
+	metadataPrefixes = []netip.Prefix{
+		netip.MustParsePrefix("169.254.169.254/32"), // AWS, GCP, Azure, OpenStack
+		netip.MustParsePrefix("169.254.170.2/32"),   // AWS ECS task metadata
+		netip.MustParsePrefix("100.100.100.200/32"), // Alibaba Cloud
+		netip.MustParsePrefix("fd00:ec2::254/128"),  // AWS IPv6
+	}
+	// privatePrefixes are rejected unless allow_private_networks is enabled or they are explicitly allowed by CIDR.
+	privatePrefixes = []netip.Prefix{
+		netip.MustParsePrefix("0.0.0.0/8"),
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("100.64.0.0/10"),
+		netip.MustParsePrefix("127.0.0.0/8"),
+		netip.MustParsePrefix("169.254.0.0/16"),
+		netip.MustParsePrefix("172.16.0.0/12"),
+		netip.MustParsePrefix("192.168.0.0/16"),
+		netip.MustParsePrefix("::/128"),
+		netip.MustParsePrefix("::1/128"),
+		netip.MustParsePrefix("fc00::/7"),
+		netip.MustParsePrefix("fe80::/10"),
+	}
+)
+
+// ServerPolicyError is returned when a target server is rejected by the server policy.
+type ServerPolicyError struct {
+	// Server is the rejected target server (host or URL).
+	Server string
+	// Address is the resolved IP address that was rejected, if any.
+	Address string
+	// Reason describes why the server was rejected.
+	Reason string
+}
+
+func (e *ServerPolicyError) Error() string {
+	if e.Address != "" {
+		return fmt.Sprintf("target server %q (%s) rejected: %s", e.Server, e.Address, e.Reason)
+	}
+	return fmt.Sprintf("target server %q rejected: %s", e.Server, e.Reason)
+}
+
+// serverPattern is a parsed target server pattern: an exact host, a domain suffix or a CIDR.
+type serverPattern struct {
+	host   string
+	suffix string
+	prefix netip.Prefix
+}
+
+func newServerPatterns(patterns []string) []serverPattern {
+	ret := make([]serverPattern, 0, len(patterns))
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		switch {
+		case strings.Contains(pattern, "/"):
+			if prefix, err := netip.ParsePrefix(pattern); err == nil {
+				ret = append(ret, serverPattern{prefix: prefix.Masked()})
+			}
+		case strings.HasPrefix(pattern, "*."):
+			ret = append(ret, serverPattern{suffix: strings.TrimPrefix(pattern, "*")})
+		case pattern != "":
+			ret = append(ret, serverPattern{host: pattern})
+		}
+	}
+	return ret
+}
+
+func (p serverPattern) matchesHost(host string) bool {
+	switch {
+	case p.host != "":
+		return p.host == host
+	case p.suffix != "":
+		return strings.HasSuffix(host, p.suffix)
+	case p.prefix.IsValid():
+		addr, err := netip.ParseAddr(host)
+		return err == nil && p.prefix.Contains(addr.Unmap())
+	}
+	return false
+}
+
+func (p serverPattern) matchesAddr(addr netip.Addr) bool {
+	return p.prefix.IsValid() && p.prefix.Contains(addr.Unmap())
+}
+
+// resolver resolves host names, it is satisfied by *net.Resolver.
+type resolver interface {
+	LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error)
+}
+
+// ServerPolicy decides which target servers the auth-headers provider may connect to.
+type ServerPolicy struct {
+	allowed               []serverPattern
+	denied                []serverPattern
+	denyPrivateNetworks   bool
+	insecureSkipTLSVerify string
+	insecureAllowed       []serverPattern
+	forbidProxyOverride   bool
+	resolver              resolver
+}
+
+// NewServerPolicy creates the server policy from the auth-headers provider configuration.
+func NewServerPolicy(cfg *config.AuthHeadersProviderConfig) *ServerPolicy {
+	return &ServerPolicy{
+		allowed:               newServerPatterns(cfg.AllowedServers),
+		denied:                newServerPatterns(cfg.DeniedServers),
+		denyPrivateNetworks:   !cfg.AllowPrivateNetworks,
+		insecureSkipTLSVerify: cfg.GetInsecureSkipTLSVerify(),
+		insecureAllowed:       newServerPatterns(cfg.InsecureSkipTLSVerifyServers),
+		forbidProxyOverride:   cfg.ForbidProxyURLOverride,
+		resolver:              net.DefaultResolver,
+	}
+}
+
+// CheckServer validates the target server URL against the allowlist and denylist and checks every address
+// it resolves to. It must be called before any client is built for the server.
+func (p *ServerPolicy) CheckServer(ctx context.Context, server string) error {
+	u, err := url.Parse(server)
+	if err != nil || u.Host == "" {
+		return &ServerPolicyError{Server: server, Reason: "server must be an absolute URL"}
+	}
+	if u.Scheme != "https" {
+		return &ServerPolicyError{Server: server, Reason: "only https servers are allowed"}
+	}
+	if u.User != nil {
+		return &ServerPolicyError{Server: server, Reason: "server URL must not contain user info"}
+	}
+	return p.CheckHost(ctx, u.Hostname())
+}
+
+// CheckInsecureSkipTLSVerify validates that TLS verification may be skipped for the target server.
+func (p *ServerPolicy) CheckInsecureSkipTLSVerify(server string) error {
+	switch p.insecureSkipTLSVerify {
+	case config.InsecureSkipTLSVerifyAllow:
+		return nil
+	case config.InsecureSkipTLSVerifyAllowlisted:
+		u, err := url.Parse(server)
+		if err == nil {
+			host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+			for _, pattern := range p.insecureAllowed {
+				if pattern.matchesHost(host) {
+					return nil
+				}
+			}
+		}
+		return &ServerPolicyError{Server: server, Reason: fmt.Sprintf("%s is only allowed for allowlisted servers, provide %s instead", CustomInsecureSkipTLSVerifyHeader, CustomCertificateAuthorityDataHeader)}
+	default:
+		return &ServerPolicyError{Server: server, Reason: fmt.Sprintf("%s is forbidden by the server configuration, provide %s instead", CustomInsecureSkipTLSVerifyHeader, CustomCertificateAuthorityDataHeader)}
+	}
+}
+
+// CheckProxyURL validates a per-request proxy URL. The proxy host is subject to the same checks as the
+// target servers, since the server connects to it on behalf of the caller.
+func (p *ServerPolicy) CheckProxyURL(ctx context.Context, proxyURL string) error {
+	if err := config.ValidateProxyURL(proxyURL); err != nil {
+		return &ServerPolicyError{Server: proxyURL, Reason: fmt.Sprintf("invalid proxy URL: %v", err)}
+	}
+	u, _ := url.Parse(proxyURL)
+	if p.forbidProxyOverride {
+		return &ServerPolicyError{Server: u.Redacted(), Reason: fmt.Sprintf("%s is forbidden by the server configuration", CustomProxyURLHeader)}
+	}
+	return p.CheckHost(ctx, u.Hostname())
+}
+
+// CheckHost validates the host against the allowlist and denylist and checks every address it resolves to.
+func (p *ServerPolicy) CheckHost(ctx context.Context, host string) error {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	for _, pattern := range p.denied {
+		if pattern.matchesHost(host) {
+			return &ServerPolicyError{Server: host, Reason: "server is denied"}
+		}
+	}
+	if len(p.allowed) > 0 && !p.isHostAllowed(ctx, host) {
+		return &ServerPolicyError{Server: host, Reason: "server is not in the allowlist"}
+	}
+
+	addrs, err := p.resolve(ctx, host)
+	if err != nil {
+		return &ServerPolicyError{Server: host, Reason: fmt.Sprintf("failed to resolve server: %v", err)}
+	}
+	for _, addr := range addrs {
+		if err := p.CheckAddr(addr); err != nil {
+			var policyErr *ServerPolicyError
+			if errors.As(err, &policyErr) {
+				policyErr.Server = host
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckAddr validates a resolved address against the denylist and the built-in denied ranges.
+func (p *ServerPolicy) CheckAddr(addr netip.Addr) error {
+	addr = addr.Unmap()
+	for _, pattern := range p.denied {
+		if pattern.matchesAddr(addr) {
+			return &ServerPolicyError{Address: addr.String(), Reason: "address is denied"}
+		}
+	}
+	if p.isAddrExplicitlyAllowed(addr) {
+		return nil
+	}
+	for _, prefix := range metadataPrefixes {
+		if prefix.Contains(addr) {
+			return &ServerPolicyError{Address: addr.String(), Reason: "cloud metadata endpoints are not allowed"}
+		}
+	}
+	if p.denyPrivateNetworks {
+		for _, prefix := range privatePrefixes {
+			if prefix.Contains(addr) {
+				return &ServerPolicyError{Address: addr.String(), Reason: "private, loopback and link-local addresses are not allowed"}
+			}
+		}
+	}
+	return nil
+}
+
+// DialContext dials the address after checking the resolved IP addresses against the policy.
+// It is used as the transport dialer so that DNS answers changing after CheckServer cannot bypass the policy.
+func (p *ServerPolicy) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	return p.dialContext(ctx, network, address, "")
+}
+
+// DialContextWithTrustedProxy returns a dialer like DialContext, except that connections to the proxy
+// configured on the server are not checked, since it may live in a private network.
+func (p *ServerPolicy) DialContextWithTrustedProxy(proxyURL *url.URL) func(ctx context.Context, network, address string) (net.Conn, error) {
+	trusted := net.JoinHostPort(strings.ToLower(proxyURL.Hostname()), proxyPort(proxyURL))
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		return p.dialContext(ctx, network, address, trusted)
+	}
+}
+
+func (p *ServerPolicy) dialContext(ctx context.Context, network, address, trusted string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := p.resolve(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	var dialErr error
+	for _, addr := range addrs {
+		if trusted != "" && net.JoinHostPort(strings.ToLower(host), port) == trusted {
+			// the configured proxy is trusted, the target server was checked before
+			break
+		}
+		if err := p.CheckAddr(addr); err != nil {
+			var policyErr *ServerPolicyError
+			if errors.As(err, &policyErr) {
+				policyErr.Server = host
+			}
+			return nil, err
+		}
+	}
+	for _, addr := range addrs {
+		conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(addr.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		dialErr = err
+	}
+	return nil, dialErr
+}
+
+// proxyPort returns the port of the proxy URL or the default port of its scheme.
+func proxyPort(proxyURL *url.URL) string {
+	if port := proxyURL.Port(); port != "" {
+		return port
+	}
+	switch proxyURL.Scheme {
+	case "https":
+		return "443"
+	case "socks5", "socks5h":
+		return "1080"
+	}
+	return "80"
+}
+
+func (p *ServerPolicy) isHostAllowed(ctx context.Context, host string) bool {
+	for _, pattern := range p.allowed {
+		if pattern.matchesHost(host) {
+			return true
+		}
+	}
+	// CIDR entries also allow hostnames resolving into the range
+	addrs, err := p.resolve(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		return false
+	}
+	for _, addr := range addrs {
+		if !p.isAddrExplicitlyAllowed(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *ServerPolicy) isAddrExplicitlyAllowed(addr netip.Addr) bool {
+	for _, pattern := range p.allowed {
+		if pattern.matchesAddr(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *ServerPolicy) resolve(ctx context.Context, host string) ([]netip.Addr, error) {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return []netip.Addr{addr.Unmap()}, nil
+	}
+	addrs, err := p.resolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil, err
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no addresses found for %s", host)
+	}
+	return addrs, nil
+}
@@ -0,0 +1,80 @@
+package kubernetes
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/rest"
+)
+
+type targetCacheEntry[T any] struct {
+	value     T
+	expiresAt time.Time
+}
+
+// targetCache is a bounded, TTL-based cache of resolved values, keyed by a fingerprint of the target cluster and
+// identity (see targetKey) or of the verified token.
+type targetCache[T any] struct {
+	mu         sync.Mutex
+	entries    map[string]*targetCacheEntry[T]
+	ttl        time.Duration
+	maxEntries int
+}
+
+func newTargetCache[T any](ttl time.Duration, maxEntries int) *targetCache[T] {
+	return &targetCache[T]{entries: make(map[string]*targetCacheEntry[T]), ttl: ttl, maxEntries: maxEntries}
+}
+
+// getOrResolve returns the cached value of the key or resolves (and caches) it with resolve.
+// Resolution errors are not cached.
+func (c *targetCache[T]) getOrResolve(key string, resolve func() (T, error)) (T, error) {
+	now := time.Now()
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.value, nil
+	}
+
+	value, err := resolve()
+	if err != nil {
+		return value, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= c.maxEntries {
+		for k, e := range c.entries {
+			if !now.Before(e.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= c.maxEntries {
+			c.entries = make(map[string]*targetCacheEntry[T])
+		}
+	}
+	c.entries[key] = &targetCacheEntry[T]{value: value, expiresAt: now.Add(c.ttl)}
+	return value, nil
+}
+
+// targetKey identifies the target cluster and the identity of the REST config.
+// The identity is part of the key since what is resolved (e.g. the Gardener shoot info, the Kyma CR) is subject to RBAC.
+func targetKey(restConfig *rest.Config) string {
+	h := sha256.New()
+	for _, field := range []string{
+		restConfig.Host,
+		restConfig.TLSClientConfig.ServerName,
+		restConfig.BearerToken,
+		string(restConfig.TLSClientConfig.CertData),
+		restConfig.Impersonate.UserName,
+		restConfig.Impersonate.UID,
+		strings.Join(restConfig.Impersonate.Groups, "\n"),
+	} {
+		_, _ = fmt.Fprintf(h, "%d:%s", len(field), field)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
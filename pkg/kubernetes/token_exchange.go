@@ -0,0 +1,334 @@
+package kubernetes
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containers/kubernetes-mcp-server/pkg/tokenexchange"
+	"github.com/mfaizanse/ext-kyma-mcp/pkg/config"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// exchangedTokenDefaultTTL is how long an exchanged token without expiry is reused.
+	exchangedTokenDefaultTTL = 5 * time.Minute
+	// exchangedTokenExpiryDelta is how long before its expiry an exchanged token is exchanged again.
+	exchangedTokenExpiryDelta = 30 * time.Second
+	// exchangedTokenMaxEntries is the maximum number of exchanged tokens kept in memory.
+	exchangedTokenMaxEntries = 1000
+
+	wellKnownOpenIDConfiguration = "/.well-known/openid-configuration"
+)
+
+type exchangedToken struct {
+	accessToken string
+	expiresAt   time.Time
+}
+
+// tokenExchanger exchanges the bearer token of the auth headers (the caller IdP token) for a cluster-scoped
+// token, and caches the exchanged tokens until they expire.
+type tokenExchanger struct {
+	config *config.AuthHeadersTokenExchangeConfig
+	// clusterConfig is used with the issuers discovered from the target clusters, its http client dials through
+	// the server policy and does not follow redirects.
+	clusterConfig  *config.AuthHeadersTokenExchangeConfig
+	allowedIssuers []serverPattern
+	exchanger      tokenexchange.TokenExchanger
+	serverPolicy   *ServerPolicy
+	now            func() time.Time
+
+	mu             sync.Mutex
+	tokens         map[string]*exchangedToken
+	tokenURLs      map[string]string
+	clusterIssuers map[string]string
+}
+
+// newTokenExchanger creates the token exchanger, nil is returned if token exchange is not configured.
+func newTokenExchanger(cfg *config.AuthHeadersTokenExchangeConfig, serverPolicy *ServerPolicy) (*tokenExchanger, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	exchanger, ok := tokenexchange.GetTokenExchanger(cfg.GetStrategy())
+	if !ok {
+		return nil, fmt.Errorf("token exchange strategy %q is not supported", cfg.GetStrategy())
+	}
+	// the exchangers read the subject token type from the target configuration
+	exchangeConfig := *cfg
+	exchangeConfig.SubjectTokenType = cfg.GetSubjectTokenType()
+	// create the IdP http client once, copies of the configuration share it
+	if _, err := exchangeConfig.HTTPCLient(); err != nil {
+		return nil, fmt.Errorf("failed to create the token exchange http client: %w", err)
+	}
+	var clusterConfig *config.AuthHeadersTokenExchangeConfig
+	if exchangeConfig.UseClusterIssuer {
+		var err error
+		if clusterConfig, err = newClusterIssuerConfig(&exchangeConfig, serverPolicy); err != nil {
+			return nil, fmt.Errorf("failed to create the cluster issuer http client: %w", err)
+		}
+	}
+	return &tokenExchanger{
+		config:         &exchangeConfig,
+		clusterConfig:  clusterConfig,
+		allowedIssuers: newServerPatterns(cfg.AllowedClusterIssuers),
+		exchanger:      exchanger,
+		serverPolicy:   serverPolicy,
+		now:            time.Now,
+		tokens:         make(map[string]*exchangedToken),
+		tokenURLs:      make(map[string]string),
+		clusterIssuers: make(map[string]string),
+	}, nil
+}
+
+// newClusterIssuerConfig copies the token exchange configuration with an http client of its own, which dials
+// through the server policy and does not follow redirects, for the issuers discovered from the target clusters.
+func newClusterIssuerConfig(cfg *config.AuthHeadersTokenExchangeConfig, serverPolicy *ServerPolicy) (*config.AuthHeadersTokenExchangeConfig, error) {
+	// the exported fields only, the http client of the configuration is not shared
+	ret := &config.AuthHeadersTokenExchangeConfig{
+		TargetTokenExchangeConfig: tokenexchange.TargetTokenExchangeConfig{
+			TokenURL:         cfg.TokenURL,
+			ClientID:         cfg.ClientID,
+			ClientSecret:     cfg.ClientSecret,
+			Audience:         cfg.Audience,
+			SubjectTokenType: cfg.SubjectTokenType,
+			SubjectIssuer:    cfg.SubjectIssuer,
+			Scopes:           cfg.Scopes,
+			CAFile:           cfg.CAFile,
+			AuthStyle:        cfg.AuthStyle,
+		},
+		Strategy:              cfg.Strategy,
+		IssuerURL:             cfg.IssuerURL,
+		UseClusterIssuer:      cfg.UseClusterIssuer,
+		AllowedClusterIssuers: cfg.AllowedClusterIssuers,
+	}
+	httpClient, err := ret.HTTPCLient()
+	if err != nil {
+		return nil, err
+	}
+	transport, ok := httpClient.Transport.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("unexpected http transport %T", httpClient.Transport)
+	}
+	transport.DialContext = serverPolicy.DialContext
+	httpClient.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return ret, nil
+}
+
+// exchange returns a copy of the auth headers carrying the exchanged token.
+// The auth headers must have been checked against the server policy, since the cluster issuer is discovered
+// from the target server.
+func (e *tokenExchanger) exchange(ctx context.Context, authHeaders *K8sAuthHeaders) (*K8sAuthHeaders, error) {
+	if authHeaders.AuthorizationToken == "" {
+		return nil, fmt.Errorf("token exchange requires %s", CustomAuthorizationHeader)
+	}
+	tokenURL, exchangeConfig, err := e.tokenURL(ctx, authHeaders)
+	if err != nil {
+		return nil, err
+	}
+
+	key := e.cacheKey(tokenURL, authHeaders.AuthorizationToken)
+	now := e.now()
+	e.mu.Lock()
+	cached, ok := e.tokens[key]
+	e.mu.Unlock()
+	if !ok || !now.Before(cached.expiresAt) {
+		cached, err = e.doExchange(ctx, exchangeConfig, tokenURL, authHeaders.AuthorizationToken)
+		if err != nil {
+			return nil, err
+		}
+		e.mu.Lock()
+		if len(e.tokens) >= exchangedTokenMaxEntries {
+			for k, token := range e.tokens {
+				if !now.Before(token.expiresAt) {
+					delete(e.tokens, k)
+				}
+			}
+			if len(e.tokens) >= exchangedTokenMaxEntries {
+				e.tokens = make(map[string]*exchangedToken)
+			}
+		}
+		e.tokens[key] = cached
+		e.mu.Unlock()
+	}
+
+	exchanged := *authHeaders
+	exchanged.AuthorizationToken = cached.accessToken
+	return &exchanged, nil
+}
+
+func (e *tokenExchanger) doExchange(ctx context.Context, cfg *config.AuthHeadersTokenExchangeConfig, tokenURL, subjectToken string) (*exchangedToken, error) {
+	exchangeConfig := *cfg
+	exchangeConfig.TokenURL = tokenURL
+	token, err := e.exchanger.Exchange(ctx, &exchangeConfig.TargetTokenExchangeConfig, subjectToken)
+	if err != nil {
+		return nil, fmt.Errorf("token exchange failed: %w", err)
+	}
+	if token.AccessToken == "" {
+		return nil, errors.New("token exchange failed: no access token returned")
+	}
+
+	expiresAt := e.now().Add(exchangedTokenDefaultTTL)
+	if !token.Expiry.IsZero() {
+		expiresAt = token.Expiry.Add(-exchangedTokenExpiryDelta)
+	}
+	klog.V(4).Infof("token exchanged successfully at %s, valid until %s", tokenURL, expiresAt.Format(time.RFC3339))
+	return &exchangedToken{accessToken: token.AccessToken, expiresAt: expiresAt}, nil
+}
+
+// tokenURL returns the configured token endpoint or discovers it from the configured or the cluster issuer,
+// along with the configuration to exchange the token with.
+func (e *tokenExchanger) tokenURL(ctx context.Context, authHeaders *K8sAuthHeaders) (string, *config.AuthHeadersTokenExchangeConfig, error) {
+	if e.config.TokenURL != "" {
+		return e.config.TokenURL, e.config, nil
+	}
+
+	exchangeConfig, issuer := e.config, e.config.IssuerURL
+	if issuer == "" {
+		var err error
+		if issuer, err = e.clusterIssuer(ctx, authHeaders); err != nil {
+			return "", nil, err
+		}
+		exchangeConfig = e.clusterConfig
+	}
+
+	e.mu.Lock()
+	tokenURL, ok := e.tokenURLs[issuer]
+	e.mu.Unlock()
+	if ok {
+		return tokenURL, exchangeConfig, nil
+	}
+
+	httpClient, err := exchangeConfig.HTTPCLient()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create the token exchange http client: %w", err)
+	}
+	var discovery struct {
+		Issuer        string `json:"issuer"`
+		TokenEndpoint string `json:"token_endpoint"`
+	}
+	if err := getJSON(ctx, httpClient, strings.TrimSuffix(issuer, "/")+wellKnownOpenIDConfiguration, &discovery); err != nil {
+		return "", nil, fmt.Errorf("failed to discover the token endpoint of issuer %s: %w", issuer, err)
+	}
+	if discovery.TokenEndpoint == "" {
+		return "", nil, fmt.Errorf("issuer %s does not publish a token endpoint", issuer)
+	}
+	if exchangeConfig == e.clusterConfig {
+		// the subject token and the client credentials are posted to the token endpoint
+		if err := e.checkClusterIssuer(discovery.TokenEndpoint); err != nil {
+			return "", nil, fmt.Errorf("token endpoint of issuer %s rejected: %w", issuer, err)
+		}
+	}
+
+	e.mu.Lock()
+	e.tokenURLs[issuer] = discovery.TokenEndpoint
+	e.mu.Unlock()
+	return discovery.TokenEndpoint, exchangeConfig, nil
+}
+
+// clusterIssuer discovers the OIDC issuer of the target cluster.
+func (e *tokenExchanger) clusterIssuer(ctx context.Context, authHeaders *K8sAuthHeaders) (string, error) {
+	if !e.config.UseClusterIssuer {
+		return "", errors.New("token exchange requires token_url, issuer_url or use_cluster_issuer")
+	}
+	e.mu.Lock()
+	issuer, ok := e.clusterIssuers[authHeaders.Server]
+	e.mu.Unlock()
+	if ok {
+		return issuer, nil
+	}
+
+	httpClient, err := rest.HTTPClientFor(&rest.Config{
+		Host: authHeaders.Server,
+		TLSClientConfig: rest.TLSClientConfig{
+			Insecure:   authHeaders.InsecureSkipTLSVerify,
+			ServerName: authHeaders.TLSServerName,
+			CAData:     authHeaders.CertificateAuthorityData,
+		},
+		Dial: e.serverPolicy.DialContext,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create the cluster http client: %w", err)
+	}
+	var discovery struct {
+		Issuer string `json:"issuer"`
+	}
+	if err := getJSON(ctx, httpClient, strings.TrimSuffix(authHeaders.Server, "/")+wellKnownOpenIDConfiguration, &discovery); err != nil {
+		return "", fmt.Errorf("failed to discover the OIDC issuer of the cluster: %w", err)
+	}
+	if discovery.Issuer == "" {
+		return "", errors.New("the cluster does not publish an OIDC issuer")
+	}
+	if err := e.checkClusterIssuer(discovery.Issuer); err != nil {
+		return "", fmt.Errorf("the OIDC issuer of the cluster is rejected: %w", err)
+	}
+
+	e.mu.Lock()
+	e.clusterIssuers[authHeaders.Server] = discovery.Issuer
+	e.mu.Unlock()
+	return discovery.Issuer, nil
+}
+
+// checkClusterIssuer validates a URL published by the target cluster (its issuer, or the token endpoint of the
+// issuer) against allowed_cluster_issuers. The addresses it resolves to are checked when dialing.
+func (e *tokenExchanger) checkClusterIssuer(issuerURL string) error {
+	u, err := url.Parse(issuerURL)
+	if err != nil || u.Host == "" {
+		return &ServerPolicyError{Server: issuerURL, Reason: "issuer must be an absolute URL"}
+	}
+	if u.Scheme != "https" {
+		return &ServerPolicyError{Server: issuerURL, Reason: "only https issuers are allowed"}
+	}
+	if u.User != nil {
+		return &ServerPolicyError{Server: u.Redacted(), Reason: "issuer URL must not contain user info"}
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	for _, pattern := range e.allowedIssuers {
+		if pattern.matchesHost(host) {
+			return nil
+		}
+	}
+	return &ServerPolicyError{Server: host, Reason: "issuer is not in allowed_cluster_issuers"}
+}
+
+// cacheKey identifies the exchanged token of a subject token, the subject token is never stored in cleartext.
+func (e *tokenExchanger) cacheKey(tokenURL, subjectToken string) string {
+	digest := sha256.New()
+	writeField(digest, []byte(tokenURL))
+	writeField(digest, []byte(e.config.Audience))
+	writeField(digest, []byte(strings.Join(e.config.Scopes, " ")))
+	writeField(digest, []byte(subjectToken))
+	return hex.EncodeToString(digest.Sum(nil))
+}
+
+func getJSON(ctx context.Context, httpClient *http.Client, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return json.Unmarshal(body, v)
+}
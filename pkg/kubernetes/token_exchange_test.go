@@ -0,0 +1,285 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/containers/kubernetes-mcp-server/pkg/tokenexchange"
+	"github.com/mfaizanse/ext-kyma-mcp/pkg/config"
+)
+
+// fakeOIDC is a stand-in OIDC server exchanging a subject token for "exchanged:<subject token>:<audience>".
+type fakeOIDC struct {
+	*httptest.Server
+	// issuer and tokenEndpoint override the published URLs if set.
+	issuer        string
+	tokenEndpoint string
+	expiresIn     int
+
+	mu            sync.Mutex
+	discoveries   int
+	tokenRequests []url.Values
+}
+
+func newFakeOIDC(t *testing.T, tls bool) *fakeOIDC {
+	t.Helper()
+	ret := &fakeOIDC{expiresIn: 300}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+wellKnownOpenIDConfiguration, func(w http.ResponseWriter, _ *http.Request) {
+		ret.mu.Lock()
+		ret.discoveries++
+		issuer, tokenEndpoint := ret.issuer, ret.tokenEndpoint
+		ret.mu.Unlock()
+		if issuer == "" {
+			issuer = ret.URL
+		}
+		if tokenEndpoint == "" {
+			tokenEndpoint = ret.URL + "/token"
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"issuer": issuer, "token_endpoint": tokenEndpoint})
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		ret.mu.Lock()
+		ret.tokenRequests = append(ret.tokenRequests, r.PostForm)
+		expiresIn := ret.expiresIn
+		ret.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token":      "exchanged:" + r.PostForm.Get("subject_token") + ":" + r.PostForm.Get("audience"),
+			"token_type":        "Bearer",
+			"issued_token_type": tokenexchange.TokenTypeAccessToken,
+			"expires_in":        expiresIn,
+		})
+	})
+	if tls {
+		ret.Server = httptest.NewTLSServer(mux)
+	} else {
+		ret.Server = httptest.NewServer(mux)
+	}
+	t.Cleanup(ret.Close)
+	return ret
+}
+
+func (f *fakeOIDC) discoveryCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.discoveries
+}
+
+func (f *fakeOIDC) requests() []url.Values {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]url.Values(nil), f.tokenRequests...)
+}
+
+// certificatePEM returns the certificate of the TLS test servers, all of them share the same certificate.
+func certificatePEM(server *httptest.Server) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+}
+
+func newTestTokenExchanger(t *testing.T, cfg *config.AuthHeadersTokenExchangeConfig, providerConfig *config.AuthHeadersProviderConfig) *tokenExchanger {
+	t.Helper()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("invalid token exchange configuration: %v", err)
+	}
+	ret, err := newTokenExchanger(cfg, NewServerPolicy(providerConfig))
+	if err != nil {
+		t.Fatalf("failed to create the token exchanger: %v", err)
+	}
+	return ret
+}
+
+func exchangeToken(t *testing.T, e *tokenExchanger, authHeaders *K8sAuthHeaders) string {
+	t.Helper()
+	exchanged, err := e.exchange(t.Context(), authHeaders)
+	if err != nil {
+		t.Fatalf("token exchange failed: %v", err)
+	}
+	return exchanged.AuthorizationToken
+}
+
+func TestTokenExchangeDiscoveryAndRequest(t *testing.T) {
+	idp := newFakeOIDC(t, false)
+	e := newTestTokenExchanger(t, &config.AuthHeadersTokenExchangeConfig{
+		TargetTokenExchangeConfig: tokenexchange.TargetTokenExchangeConfig{
+			ClientID:     "ext-kyma-mcp",
+			ClientSecret: "client-secret",
+			Audience:     "kubernetes",
+			Scopes:       []string{"openid", "groups"},
+		},
+		IssuerURL: idp.URL,
+	}, &config.AuthHeadersProviderConfig{})
+
+	authHeaders := &K8sAuthHeaders{Server: "https://api.example.com", AuthorizationToken: "subject"}
+	if token := exchangeToken(t, e, authHeaders); token != "exchanged:subject:kubernetes" {
+		t.Errorf("expected the exchanged token, got %q", token)
+	}
+	if authHeaders.AuthorizationToken != "subject" {
+		t.Errorf("expected the auth headers to be left unchanged, got token %q", authHeaders.AuthorizationToken)
+	}
+	if discoveries := idp.discoveryCount(); discoveries != 1 {
+		t.Errorf("expected the token endpoint to be discovered once, got %d discoveries", discoveries)
+	}
+
+	requests := idp.requests()
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 token request, got %d", len(requests))
+	}
+	for key, expected := range map[string]string{
+		"grant_type":           tokenexchange.GrantTypeTokenExchange,
+		"subject_token":        "subject",
+		"subject_token_type":   tokenexchange.TokenTypeAccessToken,
+		"requested_token_type": tokenexchange.TokenTypeAccessToken,
+		"audience":             "kubernetes",
+		"scope":                "openid groups",
+		"client_id":            "ext-kyma-mcp",
+		"client_secret":        "client-secret",
+	} {
+		if actual := requests[0].Get(key); actual != expected {
+			t.Errorf("expected %s %q, got %q", key, expected, actual)
+		}
+	}
+}
+
+func TestTokenExchangeCachesUntilExpiry(t *testing.T) {
+	idp := newFakeOIDC(t, false)
+	idp.expiresIn = 120
+	e := newTestTokenExchanger(t, &config.AuthHeadersTokenExchangeConfig{
+		TargetTokenExchangeConfig: tokenexchange.TargetTokenExchangeConfig{Audience: "kubernetes"},
+		IssuerURL:                 idp.URL,
+	}, &config.AuthHeadersProviderConfig{})
+	now := time.Now()
+	e.now = func() time.Time { return now }
+	authHeaders := &K8sAuthHeaders{Server: "https://api.example.com", AuthorizationToken: "subject"}
+
+	exchangeToken(t, e, authHeaders)
+	exchangeToken(t, e, authHeaders)
+	if requests := len(idp.requests()); requests != 1 {
+		t.Fatalf("expected the exchanged token to be reused, got %d token requests", requests)
+	}
+
+	// still valid, the token is exchanged again exchangedTokenExpiryDelta before it expires
+	now = now.Add(time.Minute)
+	exchangeToken(t, e, authHeaders)
+	if requests := len(idp.requests()); requests != 1 {
+		t.Fatalf("expected the exchanged token to be reused before its expiry, got %d token requests", requests)
+	}
+
+	now = now.Add(120*time.Second - exchangedTokenExpiryDelta)
+	exchangeToken(t, e, authHeaders)
+	if requests := len(idp.requests()); requests != 2 {
+		t.Fatalf("expected the token to be exchanged again after its expiry, got %d token requests", requests)
+	}
+	if discoveries := idp.discoveryCount(); discoveries != 1 {
+		t.Errorf("expected the discovered token endpoint to be reused, got %d discoveries", discoveries)
+	}
+}
+
+func TestTokenExchangeCacheKeys(t *testing.T) {
+	idp := newFakeOIDC(t, false)
+	e := newTestTokenExchanger(t, &config.AuthHeadersTokenExchangeConfig{
+		TargetTokenExchangeConfig: tokenexchange.TargetTokenExchangeConfig{TokenURL: idp.URL + "/token", Audience: "kubernetes"},
+	}, &config.AuthHeadersProviderConfig{})
+
+	tokenA := exchangeToken(t, e, &K8sAuthHeaders{Server: "https://api.example.com", AuthorizationToken: "a"})
+	tokenB := exchangeToken(t, e, &K8sAuthHeaders{Server: "https://api.example.com", AuthorizationToken: "b"})
+	if tokenA != "exchanged:a:kubernetes" || tokenB != "exchanged:b:kubernetes" {
+		t.Errorf("expected a token per subject token, got %q and %q", tokenA, tokenB)
+	}
+	if again := exchangeToken(t, e, &K8sAuthHeaders{Server: "https://api.example.com", AuthorizationToken: "a"}); again != tokenA {
+		t.Errorf("expected the token of the subject token to be reused, got %q", again)
+	}
+	if requests := len(idp.requests()); requests != 2 {
+		t.Fatalf("expected a token request per subject token, got %d", requests)
+	}
+
+	e.config.Audience = "other"
+	if token := exchangeToken(t, e, &K8sAuthHeaders{Server: "https://api.example.com", AuthorizationToken: "a"}); token != "exchanged:a:other" {
+		t.Errorf("expected a token per audience, got %q", token)
+	}
+	if requests := len(idp.requests()); requests != 3 {
+		t.Fatalf("expected a token request per audience, got %d", requests)
+	}
+}
+
+func TestTokenExchangeClusterIssuer(t *testing.T) {
+	idp := newFakeOIDC(t, true)
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, certificatePEM(idp.Server), 0o600); err != nil {
+		t.Fatalf("failed to write the CA file: %v", err)
+	}
+	idpURL, _ := url.Parse(idp.URL)
+
+	tests := []struct {
+		name           string
+		allowPrivate   bool
+		allowed        []string
+		clusterIssuer  string
+		tokenEndpoint  string
+		expectedError  string
+		tokenRequested bool
+	}{
+		{name: "allowed issuer", allowPrivate: true, allowed: []string{"127.0.0.1"}, clusterIssuer: idp.URL, tokenRequested: true},
+		{name: "issuer not in allowed_cluster_issuers", allowPrivate: true, allowed: []string{"*.example.com"}, clusterIssuer: idp.URL, expectedError: "allowed_cluster_issuers"},
+		{name: "http issuer", allowPrivate: true, allowed: []string{"127.0.0.1"}, clusterIssuer: "http://" + idpURL.Host, expectedError: "only https issuers are allowed"},
+		{name: "token endpoint not in allowed_cluster_issuers", allowPrivate: true, allowed: []string{"127.0.0.1"}, clusterIssuer: idp.URL, tokenEndpoint: "https://idp.example.com/token", expectedError: "allowed_cluster_issuers"},
+		{name: "cluster in a private network", allowed: []string{"127.0.0.1"}, clusterIssuer: idp.URL, expectedError: "private, loopback and link-local addresses are not allowed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := newFakeOIDC(t, true)
+			cluster.issuer = tt.clusterIssuer
+			idp.mu.Lock()
+			idp.tokenEndpoint, idp.tokenRequests = tt.tokenEndpoint, nil
+			idp.mu.Unlock()
+
+			e := newTestTokenExchanger(t, &config.AuthHeadersTokenExchangeConfig{
+				TargetTokenExchangeConfig: tokenexchange.TargetTokenExchangeConfig{Audience: "kubernetes", CAFile: caFile},
+				UseClusterIssuer:          true,
+				AllowedClusterIssuers:     tt.allowed,
+			}, &config.AuthHeadersProviderConfig{AllowPrivateNetworks: tt.allowPrivate})
+			exchanged, err := e.exchange(t.Context(), &K8sAuthHeaders{
+				Server:                   cluster.URL,
+				CertificateAuthorityData: certificatePEM(cluster.Server),
+				AuthorizationToken:       "subject",
+			})
+
+			if tt.expectedError == "" {
+				if err != nil {
+					t.Fatalf("token exchange failed: %v", err)
+				}
+				if exchanged.AuthorizationToken != "exchanged:subject:kubernetes" {
+					t.Errorf("expected the exchanged token, got %q", exchanged.AuthorizationToken)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Fatalf("expected an error containing %q, got %v", tt.expectedError, err)
+			}
+			if requested := len(idp.requests()) > 0; requested != tt.tokenRequested {
+				t.Errorf("expected token requested %t, got %t", tt.tokenRequested, requested)
+			}
+		})
+	}
+}
+
+func TestTokenExchangeConfigRequiresAllowedClusterIssuers(t *testing.T) {
+	cfg := &config.AuthHeadersTokenExchangeConfig{UseClusterIssuer: true}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected use_cluster_issuer without allowed_cluster_issuers to be rejected")
+	}
+	cfg.AllowedClusterIssuers = []string{"*.accounts.ondemand.com"}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected the configuration to be valid, got %v", err)
+	}
+}
@@ -0,0 +1,305 @@
+package apigateway
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/containers/kubernetes-mcp-server/pkg/api"
+	"github.com/containers/kubernetes-mcp-server/pkg/kubernetes"
+	"github.com/containers/kubernetes-mcp-server/pkg/mcplog"
+	"github.com/containers/kubernetes-mcp-server/pkg/output"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/mfaizanse/ext-kyma-mcp/pkg/toolsets/common"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	accessStrategyNoAuth  = "noAuth"
+	accessStrategyJWT     = "jwt"
+	accessStrategyExtAuth = "extAuth"
+
+	defaultRulePath = "/*"
+)
+
+// apiRuleV2Versions are the v2 APIRule versions, in order of preference, used when v2 is not the preferred version.
+var apiRuleV2Versions = []string{"v2", "v2alpha1"}
+
+func initAPIRuleApply() []api.ServerTool {
+	return []api.ServerTool{
+		{
+			Tool: api.Tool{
+				Name: "apigateway_apirule_apply",
+				Description: "Generate and create or update a v2 Kyma APIRule exposing a Service through the Gateway with the selected access strategy (noAuth, jwt or extAuth). " +
+					"The Service and its port are validated first, use dryRun to only generate the APIRule manifest.",
+				InputSchema: &jsonschema.Schema{
+					Type: "object",
+					Properties: map[string]*jsonschema.Schema{
+						"namespace": {
+							Type:        "string",
+							Description: "Namespace of the APIRule and the Service (optional, defaults to the configured namespace)",
+						},
+						"name": {
+							Type:        "string",
+							Description: "Name of the APIRule (optional, defaults to the Service name)",
+						},
+						"service": {
+							Type:        "string",
+							Description: "Name of the Service to expose",
+						},
+						"port": {
+							Type:        "integer",
+							Description: "Port of the Service to expose (optional if the Service has a single port)",
+						},
+						"host": {
+							Type:        "string",
+							Description: "Host to expose, either a short name combined with the Gateway domain (e.g. my-app) or a fully qualified host",
+						},
+						"gateway": {
+							Type:        "string",
+							Description: "Gateway as namespace/name (defaults to " + defaultGateway + ")",
+						},
+						"accessStrategy": {
+							Type:        "string",
+							Description: "Access strategy of the rule",
+							Enum:        []any{accessStrategyNoAuth, accessStrategyJWT, accessStrategyExtAuth},
+						},
+						"path": {
+							Type:        "string",
+							Description: "Path of the rule (defaults to " + defaultRulePath + ")",
+						},
+						"methods": {
+							Type:        "array",
+							Description: "HTTP methods of the rule (defaults to GET)",
+							Items:       &jsonschema.Schema{Type: "string"},
+						},
+						"issuer": {
+							Type:        "string",
+							Description: "Issuer of the JWT tokens (required for the jwt access strategy)",
+						},
+						"jwksUri": {
+							Type:        "string",
+							Description: "https URL of the JSON Web Key Set of the issuer (required for the jwt access strategy)",
+						},
+						"authorizers": {
+							Type:        "array",
+							Description: "Names of the external authorizers configured in the Istio CR (required for the extAuth access strategy)",
+							Items:       &jsonschema.Schema{Type: "string"},
+						},
+						"dryRun": {
+							Type:        "boolean",
+							Description: "If true, the APIRule manifest is only generated and returned without being applied (defaults to false)",
+						},
+					},
+					Required: []string{"service", "host", "accessStrategy"},
+				},
+				Annotations: api.ToolAnnotations{
+					Title:           "API Gateway: APIRule Create or Update",
+					ReadOnlyHint:    ptr.To(false),
+					DestructiveHint: ptr.To(true),
+					IdempotentHint:  ptr.To(true),
+					OpenWorldHint:   ptr.To(true),
+				},
+			},
+			Handler: apiRuleApply,
+		},
+	}
+}
+
+type apiRuleOptions struct {
+	namespace      string
+	name           string
+	service        string
+	port           int
+	host           string
+	gateway        string
+	accessStrategy string
+	path           string
+	methods        []string
+	issuer         string
+	jwksURI        string
+	authorizers    []string
+}
+
+func apiRuleApply(params api.ToolHandlerParams) (*api.ToolCallResult, error) {
+	options, err := parseAPIRuleOptions(params)
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	dryRun, err := common.GetOptionalBool(params.GetArguments(), "dryRun", false)
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+
+	gvk, err := resolveAPIRuleV2GVK(params)
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+
+	service, err := params.CoreV1().Services(options.namespace).Get(params.Context, options.service, metav1.GetOptions{})
+	if err != nil {
+		mcplog.HandleK8sError(params.Context, err, "service access")
+		return api.NewToolCallResult("", fmt.Errorf("failed to get Service %s/%s: %w", options.namespace, options.service, err)), nil
+	}
+	var ports []string
+	portFound := false
+	for _, port := range service.Spec.Ports {
+		ports = append(ports, fmt.Sprintf("%d", port.Port))
+		portFound = portFound || int(port.Port) == options.port
+	}
+	switch {
+	case options.port == 0 && len(service.Spec.Ports) == 1:
+		options.port = int(service.Spec.Ports[0].Port)
+	case options.port == 0:
+		return api.NewToolCallResult("", fmt.Errorf("the Service %s has %d ports, select one of %s", options.service, len(ports), strings.Join(ports, ", "))), nil
+	case !portFound:
+		return api.NewToolCallResult("", fmt.Errorf("the Service %s has no port %d (ports: %s)", options.service, options.port, common.ValueOrDash(strings.Join(ports, ", ")))), nil
+	}
+
+	manifest, err := output.MarshalYaml(newAPIRule(gvk, options))
+	if err != nil {
+		return api.NewToolCallResult("", fmt.Errorf("failed to marshal APIRule: %w", err)), nil
+	}
+	if dryRun {
+		return api.NewToolCallResult("# The following APIRule (YAML) has been generated, it was not applied (dry run)\n"+manifest, nil), nil
+	}
+
+	resources, err := kubernetes.NewCore(params).ResourcesCreateOrUpdate(params.Context, manifest)
+	if err != nil {
+		mcplog.HandleK8sError(params.Context, err, "apirule create or update")
+		return api.NewToolCallResult("", fmt.Errorf("failed to create or update APIRule: %w", err)), nil
+	}
+	marshalled, err := output.MarshalYaml(resources)
+	if err != nil {
+		return api.NewToolCallResult("", fmt.Errorf("failed to marshal APIRule: %w", err)), nil
+	}
+	return api.NewToolCallResult("# The following APIRule (YAML) has been created or updated successfully, "+
+		"check its state with apigateway_apirule_explain if it does not become Ready\n"+marshalled, nil), nil
+}
+
+func parseAPIRuleOptions(params api.ToolHandlerParams) (*apiRuleOptions, error) {
+	args := params.GetArguments()
+	options := &apiRuleOptions{}
+	var err error
+	if options.namespace, err = common.GetOptionalString(args, "namespace"); err != nil {
+		return nil, err
+	}
+	options.namespace = params.NamespaceOrDefault(options.namespace)
+	if options.service, err = common.GetRequiredString(args, "service"); err != nil {
+		return nil, err
+	}
+	if options.name, err = common.GetOptionalStringDefault(args, "name", options.service); err != nil {
+		return nil, err
+	}
+	if options.port, err = common.GetOptionalInt(args, "port", 0); err != nil {
+		return nil, err
+	}
+	if options.host, err = common.GetRequiredString(args, "host"); err != nil {
+		return nil, err
+	}
+	if options.gateway, err = common.GetOptionalStringDefault(args, "gateway", defaultGateway); err != nil {
+		return nil, err
+	}
+	if gatewayNamespace, gatewayName, found := strings.Cut(options.gateway, "/"); !found || gatewayNamespace == "" || gatewayName == "" {
+		return nil, fmt.Errorf("gateway must be namespace/name (e.g. %s)", defaultGateway)
+	}
+	if options.accessStrategy, err = common.GetRequiredString(args, "accessStrategy"); err != nil {
+		return nil, err
+	}
+	if options.path, err = common.GetOptionalStringDefault(args, "path", defaultRulePath); err != nil {
+		return nil, err
+	}
+	if options.methods, err = common.GetOptionalStringArray(args, "methods", []string{"GET"}); err != nil {
+		return nil, err
+	}
+
+	switch options.accessStrategy {
+	case accessStrategyNoAuth:
+	case accessStrategyJWT:
+		if options.issuer, err = common.GetRequiredString(args, "issuer"); err != nil {
+			return nil, err
+		}
+		if options.jwksURI, err = common.GetRequiredString(args, "jwksUri"); err != nil {
+			return nil, err
+		}
+		if err = validateJWKSURI(options.jwksURI); err != nil {
+			return nil, err
+		}
+	case accessStrategyExtAuth:
+		if options.authorizers, err = common.GetOptionalStringArray(args, "authorizers", nil); err != nil {
+			return nil, err
+		}
+		if len(options.authorizers) == 0 {
+			return nil, fmt.Errorf("authorizers is required for the %s access strategy", accessStrategyExtAuth)
+		}
+	default:
+		return nil, fmt.Errorf("accessStrategy must be %s, %s or %s", accessStrategyNoAuth, accessStrategyJWT, accessStrategyExtAuth)
+	}
+	return options, nil
+}
+
+// resolveAPIRuleV2GVK resolves the served v2 APIRule version, which may not be the preferred one during migrations.
+func resolveAPIRuleV2GVK(params api.ToolHandlerParams) (schema.GroupVersionKind, error) {
+	gvk, err := common.ResolveGroupVersionKind(params.DiscoveryClient(), apiRuleGroup, apiRuleKind)
+	if err != nil {
+		return gvk, err
+	}
+	if strings.HasPrefix(gvk.Version, "v2") {
+		return gvk, nil
+	}
+	for _, version := range apiRuleV2Versions {
+		resources, err := params.DiscoveryClient().ServerResourcesForGroupVersion(gvk.Group + "/" + version)
+		if err != nil {
+			continue
+		}
+		for _, resource := range resources.APIResources {
+			if resource.Kind == apiRuleKind {
+				return schema.GroupVersionKind{Group: gvk.Group, Version: version, Kind: apiRuleKind}, nil
+			}
+		}
+	}
+	return gvk, fmt.Errorf("v2 APIRules are not served (preferred version %s), upgrade the api-gateway module", gvk.Version)
+}
+
+func newAPIRule(gvk schema.GroupVersionKind, options *apiRuleOptions) *unstructured.Unstructured {
+	methods := make([]any, len(options.methods))
+	for i, method := range options.methods {
+		methods[i] = strings.ToUpper(method)
+	}
+	rule := map[string]any{
+		"path":    options.path,
+		"methods": methods,
+	}
+	switch options.accessStrategy {
+	case accessStrategyNoAuth:
+		rule["noAuth"] = true
+	case accessStrategyJWT:
+		rule["jwt"] = map[string]any{
+			"authentications": []any{map[string]any{"issuer": options.issuer, "jwksUri": options.jwksURI}},
+		}
+	case accessStrategyExtAuth:
+		authorizers := make([]any, len(options.authorizers))
+		for i, authorizer := range options.authorizers {
+			authorizers[i] = authorizer
+		}
+		rule["extAuth"] = map[string]any{"authorizers": authorizers}
+	}
+
+	apiRule := &unstructured.Unstructured{Object: map[string]any{
+		"spec": map[string]any{
+			"hosts":   []any{options.host},
+			"gateway": options.gateway,
+			"service": map[string]any{
+				"name": options.service,
+				"port": int64(options.port),
+			},
+			"rules": []any{rule},
+		},
+	}}
+	apiRule.SetGroupVersionKind(gvk)
+	apiRule.SetNamespace(options.namespace)
+	apiRule.SetName(options.name)
+	return apiRule
+}
@@ -0,0 +1,491 @@
+package apigateway
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/containers/kubernetes-mcp-server/pkg/api"
+	"github.com/containers/kubernetes-mcp-server/pkg/kubernetes"
+	"github.com/containers/kubernetes-mcp-server/pkg/mcplog"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/mfaizanse/ext-kyma-mcp/pkg/toolsets/common"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	apiRuleGroup     = "gateway.kyma-project.io"
+	apiRuleKind      = "APIRule"
+	istioGroup       = "networking.istio.io"
+	istioGatewayKind = "Gateway"
+	defaultGateway   = "kyma-system/kyma-gateway"
+)
+
+func initAPIRules() []api.ServerTool {
+	return []api.ServerTool{
+		{
+			Tool: api.Tool{
+				Name:        "apigateway_apirules_list",
+				Description: "List the Kyma APIRules with their served version, exposed hosts, target Service, Gateway and status",
+				InputSchema: &jsonschema.Schema{
+					Type: "object",
+					Properties: map[string]*jsonschema.Schema{
+						"namespace": {
+							Type:        "string",
+							Description: "Namespace of the APIRules (optional, lists the APIRules of all namespaces if not provided)",
+						},
+					},
+				},
+				Annotations: api.ToolAnnotations{
+					Title:           "API Gateway: APIRules List",
+					ReadOnlyHint:    ptr.To(true),
+					DestructiveHint: ptr.To(false),
+					OpenWorldHint:   ptr.To(true),
+				},
+			},
+			Handler: apiRulesList,
+		},
+		{
+			Tool: api.Tool{
+				Name: "apigateway_apirule_explain",
+				Description: "Explain why a Kyma APIRule is in Error or Warning state: checks the referenced Gateway, the host domains against the Gateway hosts, " +
+					"the referenced Services and ports, the rules access strategies and the JWT issuer and JWKS URI fields, and lists the APIRule events",
+				InputSchema: &jsonschema.Schema{
+					Type: "object",
+					Properties: map[string]*jsonschema.Schema{
+						"namespace": {
+							Type:        "string",
+							Description: "Namespace of the APIRule (optional, defaults to the configured namespace)",
+						},
+						"name": {
+							Type:        "string",
+							Description: "Name of the APIRule",
+						},
+					},
+					Required: []string{"name"},
+				},
+				Annotations: api.ToolAnnotations{
+					Title:           "API Gateway: APIRule Explain",
+					ReadOnlyHint:    ptr.To(true),
+					DestructiveHint: ptr.To(false),
+					OpenWorldHint:   ptr.To(true),
+				},
+			},
+			Handler: apiRuleExplain,
+		},
+	}
+}
+
+func apiRulesList(params api.ToolHandlerParams) (*api.ToolCallResult, error) {
+	namespace, err := common.GetOptionalString(params.GetArguments(), "namespace")
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	gvk, err := common.ResolveGroupVersionKind(params.DiscoveryClient(), apiRuleGroup, apiRuleKind)
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+
+	apiRules, err := common.ListResources(params, gvk, namespace)
+	if err != nil {
+		return api.NewToolCallResult("", fmt.Errorf("failed to list APIRules: %w", err)), nil
+	}
+	if len(apiRules) == 0 {
+		return api.NewToolCallResult("# No APIRules found", nil), nil
+	}
+	sort.Slice(apiRules, func(i, j int) bool {
+		if apiRules[i].GetNamespace() != apiRules[j].GetNamespace() {
+			return apiRules[i].GetNamespace() < apiRules[j].GetNamespace()
+		}
+		return apiRules[i].GetName() < apiRules[j].GetName()
+	})
+
+	buf := new(bytes.Buffer)
+	w := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "NAMESPACE\tNAME\tVERSION\tHOSTS\tSERVICE\tGATEWAY\tSTATE")
+	var problems []string
+	for i := range apiRules {
+		apiRule := &apiRules[i]
+		gateway, _, _ := unstructured.NestedString(apiRule.Object, "spec", "gateway")
+		state, description := apiRuleState(apiRule)
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			apiRule.GetNamespace(), apiRule.GetName(), gvk.Version, common.ValueOrDash(strings.Join(apiRuleHosts(apiRule), ",")),
+			common.ValueOrDash(serviceReference(apiRule.Object["spec"], apiRule.GetNamespace())), common.ValueOrDash(gateway), common.ValueOrDash(state))
+		if state != "Ready" && state != "OK" && description != "" {
+			problems = append(problems, fmt.Sprintf("- %s/%s %s: %s", apiRule.GetNamespace(), apiRule.GetName(), common.ValueOrDash(state), description))
+		}
+	}
+	_ = w.Flush()
+
+	ret := strings.TrimSpace(buf.String())
+	if len(problems) > 0 {
+		ret += "\n\n# APIRules not ready (explain them with apigateway_apirule_explain)\n" + strings.Join(problems, "\n")
+	}
+	return api.NewToolCallResult(ret, nil), nil
+}
+
+// apiRuleHosts returns the hosts of a v2 APIRule, or the host of a v1beta1 APIRule.
+func apiRuleHosts(apiRule *unstructured.Unstructured) []string {
+	if hosts, ok, _ := unstructured.NestedStringSlice(apiRule.Object, "spec", "hosts"); ok {
+		return hosts
+	}
+	if host, ok, _ := unstructured.NestedString(apiRule.Object, "spec", "host"); ok && host != "" {
+		return []string{host}
+	}
+	return nil
+}
+
+// apiRuleState returns the state and its description, for both the v2 and the v1beta1 status.
+func apiRuleState(apiRule *unstructured.Unstructured) (string, string) {
+	if state, ok, _ := unstructured.NestedString(apiRule.Object, "status", "state"); ok {
+		description, _, _ := unstructured.NestedString(apiRule.Object, "status", "description")
+		return state, description
+	}
+	code, _, _ := unstructured.NestedString(apiRule.Object, "status", "APIRuleStatus", "code")
+	description, _, _ := unstructured.NestedString(apiRule.Object, "status", "APIRuleStatus", "desc")
+	return code, description
+}
+
+// serviceReference returns the namespace/name:port of the service of an APIRule spec or rule, if any.
+func serviceReference(holder any, defaultNamespace string) string {
+	service, ok := serviceTarget(holder, defaultNamespace)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s:%d", service.namespace, service.name, service.port)
+}
+
+type serviceTargetRef struct {
+	namespace string
+	name      string
+	port      int64
+}
+
+func serviceTarget(holder any, defaultNamespace string) (serviceTargetRef, bool) {
+	holderMap, ok := holder.(map[string]any)
+	if !ok {
+		return serviceTargetRef{}, false
+	}
+	service, ok := holderMap["service"].(map[string]any)
+	if !ok {
+		return serviceTargetRef{}, false
+	}
+	ret := serviceTargetRef{namespace: defaultNamespace}
+	ret.name, _ = service["name"].(string)
+	if namespace, _ := service["namespace"].(string); namespace != "" {
+		ret.namespace = namespace
+	}
+	switch port := service["port"].(type) {
+	case int64:
+		ret.port = port
+	case float64:
+		ret.port = int64(port)
+	}
+	return ret, ret.name != ""
+}
+
+// apiRuleExplanation collects the checks of an APIRule, the first finding is the likely cause of its state.
+type apiRuleExplanation struct {
+	findings []string
+	checks   []string
+}
+
+func (e *apiRuleExplanation) ok(format string, a ...any) {
+	e.checks = append(e.checks, "- OK: "+fmt.Sprintf(format, a...))
+}
+
+func (e *apiRuleExplanation) problem(format string, a ...any) {
+	finding := fmt.Sprintf(format, a...)
+	e.findings = append(e.findings, finding)
+	e.checks = append(e.checks, "- PROBLEM: "+finding)
+}
+
+func apiRuleExplain(params api.ToolHandlerParams) (*api.ToolCallResult, error) {
+	args := params.GetArguments()
+	namespace, err := common.GetOptionalString(args, "namespace")
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	name, err := common.GetRequiredString(args, "name")
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	gvk, err := common.ResolveGroupVersionKind(params.DiscoveryClient(), apiRuleGroup, apiRuleKind)
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+
+	core := kubernetes.NewCore(params)
+	namespace = core.NamespaceOrDefault(namespace)
+	apiRule, err := core.ResourcesGet(params.Context, &gvk, namespace, name)
+	if err != nil {
+		mcplog.HandleK8sError(params.Context, err, "apirule access")
+		return api.NewToolCallResult("", fmt.Errorf("failed to get APIRule: %w", err)), nil
+	}
+
+	explanation := &apiRuleExplanation{}
+	state, description := apiRuleState(apiRule)
+	if !strings.HasPrefix(gvk.Version, "v2") {
+		explanation.checks = append(explanation.checks, fmt.Sprintf("- NOTE: the cluster serves APIRule %s, v1beta1 APIRules are deprecated in favor of v2", gvk.Version))
+	}
+
+	gatewayHosts := explanation.checkGateway(params, apiRule)
+	explanation.checkHosts(apiRule, gatewayHosts)
+	explanation.checkServices(params, apiRule)
+	explanation.checkRules(apiRule, gvk.Version)
+	// the reported description is the fallback when none of the references explains the state
+	if state != "" && state != "Ready" && state != "OK" {
+		explanation.findings = append(explanation.findings, fmt.Sprintf("the APIRule is in %s state: %s", state, common.ValueOrDash(description)))
+	}
+
+	lines := []string{fmt.Sprintf("# APIRule %s/%s (%s)", namespace, name, gvk.GroupVersion().String()),
+		"- state: " + common.ValueOrDash(state),
+		"- description: " + common.ValueOrDash(description),
+		"", "## Likely cause"}
+	if len(explanation.findings) == 0 {
+		lines = append(lines, "No problem found in the APIRule references, check the events and the api-gateway module with kyma_module_diagnose.")
+	} else {
+		lines = append(lines, explanation.findings[0])
+	}
+	lines = append(lines, "", "## Checks")
+	lines = append(lines, explanation.checks...)
+
+	events, err := common.ListEventsForResource(params, namespace, apiRuleKind, name)
+	if err != nil {
+		events = fmt.Sprintf("# Events unavailable: %v", err)
+	}
+	lines = append(lines, "", "## Events (YAML)", events)
+	return api.NewToolCallResult(strings.Join(lines, "\n"), nil), nil
+}
+
+// checkGateway checks that the referenced Istio Gateway exists and returns its hosts.
+func (e *apiRuleExplanation) checkGateway(params api.ToolHandlerParams, apiRule *unstructured.Unstructured) []string {
+	gateway, _, _ := unstructured.NestedString(apiRule.Object, "spec", "gateway")
+	if gateway == "" {
+		e.problem("the APIRule does not reference a Gateway, set spec.gateway (e.g. %s)", defaultGateway)
+		return nil
+	}
+	gatewayNamespace, gatewayName, found := strings.Cut(gateway, "/")
+	if !found || gatewayNamespace == "" || gatewayName == "" {
+		e.problem("the Gateway %q must be referenced as namespace/name (e.g. %s)", gateway, defaultGateway)
+		return nil
+	}
+
+	gvk, err := common.ResolveGroupVersionKind(params.DiscoveryClient(), istioGroup, istioGatewayKind)
+	if err != nil {
+		e.problem("Istio Gateways are not served, is the istio module enabled? %v", err)
+		return nil
+	}
+	resource, err := kubernetes.NewCore(params).ResourcesGet(params.Context, &gvk, gatewayNamespace, gatewayName)
+	if err != nil {
+		mcplog.HandleK8sError(params.Context, err, "gateway access")
+		e.problem("the Gateway %s cannot be read: %v", gateway, err)
+		return nil
+	}
+
+	var hosts []string
+	servers, _, _ := unstructured.NestedSlice(resource.Object, "spec", "servers")
+	for _, server := range servers {
+		serverHosts, _, _ := unstructured.NestedStringSlice(map[string]any{"server": server}, "server", "hosts")
+		for _, host := range serverHosts {
+			// hosts may be qualified with a namespace (namespace/host)
+			if _, unqualified, qualified := strings.Cut(host, "/"); qualified {
+				host = unqualified
+			}
+			hosts = append(hosts, host)
+		}
+	}
+	e.ok("the Gateway %s exists with hosts %s", gateway, common.ValueOrDash(strings.Join(hosts, ", ")))
+	return hosts
+}
+
+// checkHosts checks that every host is a short name combined with a Gateway wildcard domain, or a FQDN served by the Gateway.
+func (e *apiRuleExplanation) checkHosts(apiRule *unstructured.Unstructured, gatewayHosts []string) {
+	hosts := apiRuleHosts(apiRule)
+	if len(hosts) == 0 {
+		e.problem("the APIRule does not expose any host")
+		return
+	}
+	if gatewayHosts == nil {
+		return
+	}
+	for _, host := range hosts {
+		if !strings.Contains(host, ".") {
+			domain := wildcardDomain(gatewayHosts)
+			if domain == "" {
+				e.problem("the short host %s requires a Gateway with a wildcard host (*.domain), use a fully qualified host instead", host)
+				continue
+			}
+			e.ok("the short host %s is exposed as %s.%s", host, host, domain)
+			continue
+		}
+		if !gatewayServesHost(gatewayHosts, host) {
+			e.problem("the host %s does not match any Gateway host (%s)", host, strings.Join(gatewayHosts, ", "))
+			continue
+		}
+		e.ok("the host %s matches the Gateway hosts", host)
+	}
+}
+
+func wildcardDomain(gatewayHosts []string) string {
+	for _, host := range gatewayHosts {
+		if domain, ok := strings.CutPrefix(host, "*."); ok {
+			return domain
+		}
+	}
+	return ""
+}
+
+func gatewayServesHost(gatewayHosts []string, host string) bool {
+	for _, gatewayHost := range gatewayHosts {
+		switch {
+		case gatewayHost == "*", strings.EqualFold(gatewayHost, host):
+			return true
+		case strings.HasPrefix(gatewayHost, "*."):
+			if strings.HasSuffix(strings.ToLower(host), strings.ToLower(gatewayHost[1:])) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkServices checks the Services of the APIRule spec and rules, their ports and their ready endpoints.
+func (e *apiRuleExplanation) checkServices(params api.ToolHandlerParams, apiRule *unstructured.Unstructured) {
+	specService, hasSpecService := serviceTarget(apiRule.Object["spec"], apiRule.GetNamespace())
+	targets := make(map[serviceTargetRef]bool)
+	if hasSpecService {
+		targets[specService] = true
+	}
+	rules, _, _ := unstructured.NestedSlice(apiRule.Object, "spec", "rules")
+	for i, rule := range rules {
+		if service, ok := serviceTarget(rule, apiRule.GetNamespace()); ok {
+			targets[service] = true
+		} else if !hasSpecService {
+			e.problem("rule %d has no Service and the APIRule spec.service is not set", i)
+		}
+	}
+
+	for target := range targets {
+		reference := fmt.Sprintf("%s/%s", target.namespace, target.name)
+		service, err := params.CoreV1().Services(target.namespace).Get(params.Context, target.name, metav1.GetOptions{})
+		if err != nil {
+			mcplog.HandleK8sError(params.Context, err, "service access")
+			e.problem("the Service %s cannot be read: %v", reference, err)
+			continue
+		}
+		var ports []string
+		portFound := false
+		for _, port := range service.Spec.Ports {
+			ports = append(ports, fmt.Sprintf("%d", port.Port))
+			portFound = portFound || int64(port.Port) == target.port
+		}
+		if !portFound {
+			e.problem("the Service %s has no port %d (ports: %s)", reference, target.port, common.ValueOrDash(strings.Join(ports, ", ")))
+			continue
+		}
+		if len(service.Spec.Selector) > 0 && common.ServiceReadyEndpoints(params, target.namespace, target.name) == 0 {
+			e.problem("the Service %s has no ready endpoints, check that its pods are running and match the selector", reference)
+			continue
+		}
+		e.ok("the Service %s exists with port %d", reference, target.port)
+	}
+}
+
+// checkRules checks the access strategy of every rule, and the JWT issuer and JWKS URI fields.
+func (e *apiRuleExplanation) checkRules(apiRule *unstructured.Unstructured, version string) {
+	rules, _, _ := unstructured.NestedSlice(apiRule.Object, "spec", "rules")
+	if len(rules) == 0 {
+		e.problem("the APIRule has no rules")
+		return
+	}
+	for i, rule := range rules {
+		ruleMap, ok := rule.(map[string]any)
+		if !ok {
+			continue
+		}
+		path, _ := ruleMap["path"].(string)
+		if path == "" {
+			e.problem("rule %d has no path", i)
+		}
+		if !strings.HasPrefix(version, "v2") {
+			if strategies, _ := ruleMap["accessStrategies"].([]any); len(strategies) == 0 {
+				e.problem("rule %d (%s) has no accessStrategies", i, path)
+			}
+			continue
+		}
+
+		var strategies []string
+		if noAuth, _ := ruleMap["noAuth"].(bool); noAuth {
+			strategies = append(strategies, "noAuth")
+		}
+		if jwt, ok := ruleMap["jwt"].(map[string]any); ok {
+			strategies = append(strategies, "jwt")
+			e.checkAuthentications(fmt.Sprintf("rule %d (%s) jwt", i, path), jwt, true)
+		}
+		if extAuth, ok := ruleMap["extAuth"].(map[string]any); ok {
+			strategies = append(strategies, "extAuth")
+			if authorizers, _ := extAuth["authorizers"].([]any); len(authorizers) == 0 {
+				e.problem("rule %d (%s) extAuth has no authorizers", i, path)
+			}
+			if restrictions, ok := extAuth["restrictions"].(map[string]any); ok {
+				e.checkAuthentications(fmt.Sprintf("rule %d (%s) extAuth restrictions", i, path), restrictions, false)
+			}
+		}
+		switch len(strategies) {
+		case 0:
+			e.problem("rule %d (%s) has no access strategy, set one of noAuth, jwt or extAuth", i, path)
+		case 1:
+			e.ok("rule %d (%s) uses %s", i, path, strategies[0])
+		default:
+			e.problem("rule %d (%s) sets several access strategies (%s), only one of noAuth, jwt or extAuth is allowed", i, path, strings.Join(strategies, ", "))
+		}
+	}
+}
+
+func (e *apiRuleExplanation) checkAuthentications(rule string, holder map[string]any, required bool) {
+	authentications, _ := holder["authentications"].([]any)
+	if len(authentications) == 0 {
+		if required {
+			e.problem("%s has no authentications, set an issuer and a jwksUri", rule)
+		}
+		return
+	}
+	for _, authentication := range authentications {
+		authenticationMap, _ := authentication.(map[string]any)
+		issuer, _ := authenticationMap["issuer"].(string)
+		jwksURI, _ := authenticationMap["jwksUri"].(string)
+		problems := len(e.findings)
+		if issuer == "" {
+			e.problem("%s has an authentication without issuer", rule)
+		} else if _, err := url.Parse(issuer); err != nil {
+			e.problem("%s issuer %s is not a valid URI: %v", rule, issuer, err)
+		}
+		if err := validateJWKSURI(jwksURI); err != nil {
+			e.problem("%s %v", rule, err)
+		}
+		if len(e.findings) == problems {
+			e.ok("%s issuer %s with jwksUri %s", rule, issuer, jwksURI)
+		}
+	}
+}
+
+// validateJWKSURI checks that the JWKS URI is an https URL that Istio can fetch the keys from.
+func validateJWKSURI(jwksURI string) error {
+	if jwksURI == "" {
+		return fmt.Errorf("jwksUri is not set")
+	}
+	parsed, err := url.Parse(jwksURI)
+	if err != nil {
+		return fmt.Errorf("jwksUri %s is not a valid URL: %w", jwksURI, err)
+	}
+	if parsed.Scheme != "https" || parsed.Host == "" {
+		return fmt.Errorf("jwksUri %s must be an https URL with a host", jwksURI)
+	}
+	return nil
+}
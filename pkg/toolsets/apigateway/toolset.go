@@ -0,0 +1,32 @@
+package apigateway
+
+import (
+	"slices"
+
+	"github.com/containers/kubernetes-mcp-server/pkg/api"
+	"github.com/containers/kubernetes-mcp-server/pkg/toolsets"
+)
+
+type Toolset struct{}
+
+var _ api.Toolset = (*Toolset)(nil)
+
+func (t *Toolset) GetName() string {
+	return "apigateway"
+}
+
+func (t *Toolset) GetDescription() string {
+	return "Kyma API Gateway tools for authoring, exposing and troubleshooting APIRules"
+}
+
+func (t *Toolset) GetTools(api.Openshift) []api.ServerTool {
+	return slices.Concat(initAPIRules(), initAPIRuleApply())
+}
+
+func (t *Toolset) GetPrompts() []api.ServerPrompt {
+	return nil
+}
+
+func init() {
+	toolsets.Register(&Toolset{})
+}
@@ -0,0 +1,227 @@
+package btp
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/containers/kubernetes-mcp-server/pkg/api"
+	"github.com/containers/kubernetes-mcp-server/pkg/mcplog"
+	"github.com/containers/kubernetes-mcp-server/pkg/output"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/mfaizanse/ext-kyma-mcp/pkg/toolsets/common"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+)
+
+func initServiceCreate() []api.ServerTool {
+	createProperties := func(kind string) map[string]*jsonschema.Schema {
+		return map[string]*jsonschema.Schema{
+			"namespace": {
+				Type:        "string",
+				Description: "Namespace of the " + kind + " (optional, defaults to the configured namespace)",
+			},
+			"name": {
+				Type:        "string",
+				Description: "Name of the " + kind,
+			},
+			"externalName": {
+				Type:        "string",
+				Description: "Name of the " + kind + " in SAP BTP (optional, defaults to the name)",
+			},
+			"parameters": {
+				Type:        "object",
+				Description: "Parameters of the " + kind + " passed to the service broker (optional)",
+			},
+			"dryRun": {
+				Type:        "boolean",
+				Description: "If true, the " + kind + " is validated by the API server (server-side dry run) and returned without being created (defaults to false)",
+			},
+		}
+	}
+	instanceProperties := createProperties(serviceInstanceKind)
+	instanceProperties["offering"] = &jsonschema.Schema{
+		Type:        "string",
+		Description: "Name of the service offering, e.g. destination, xsuaa or objectstore",
+	}
+	instanceProperties["plan"] = &jsonschema.Schema{
+		Type:        "string",
+		Description: "Name of the service plan, e.g. lite or application",
+	}
+	bindingProperties := createProperties(serviceBindingKind)
+	bindingProperties["instance"] = &jsonschema.Schema{
+		Type:        "string",
+		Description: "Name of the ServiceInstance to bind, in the same namespace",
+	}
+	bindingProperties["secretName"] = &jsonschema.Schema{
+		Type:        "string",
+		Description: "Name of the Secret the credentials are written to (optional, defaults to the binding name)",
+	}
+
+	return []api.ServerTool{
+		{
+			Tool: api.Tool{
+				Name:        "btp_service_instance_create",
+				Description: "Create a SAP BTP ServiceInstance of a service offering and plan, with optional parameters. Check its state with btp_service_instances_list.",
+				InputSchema: &jsonschema.Schema{
+					Type:       "object",
+					Properties: instanceProperties,
+					Required:   []string{"name", "offering", "plan"},
+				},
+				Annotations: api.ToolAnnotations{
+					Title:           "BTP: Service Instance Create",
+					ReadOnlyHint:    ptr.To(false),
+					DestructiveHint: ptr.To(false),
+					IdempotentHint:  ptr.To(false),
+					OpenWorldHint:   ptr.To(true),
+				},
+			},
+			Handler: serviceInstanceCreate,
+		},
+		{
+			Tool: api.Tool{
+				Name:        "btp_service_binding_create",
+				Description: "Create a SAP BTP ServiceBinding of a ServiceInstance, the credentials are written to a Secret. Check it with btp_service_binding_inspect.",
+				InputSchema: &jsonschema.Schema{
+					Type:       "object",
+					Properties: bindingProperties,
+					Required:   []string{"name", "instance"},
+				},
+				Annotations: api.ToolAnnotations{
+					Title:           "BTP: Service Binding Create",
+					ReadOnlyHint:    ptr.To(false),
+					DestructiveHint: ptr.To(false),
+					IdempotentHint:  ptr.To(false),
+					OpenWorldHint:   ptr.To(true),
+				},
+			},
+			Handler: serviceBindingCreate,
+		},
+	}
+}
+
+func serviceInstanceCreate(params api.ToolHandlerParams) (*api.ToolCallResult, error) {
+	args := params.GetArguments()
+	offering, err := common.GetRequiredString(args, "offering")
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	plan, err := common.GetRequiredString(args, "plan")
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	return createService(params, serviceInstanceKind, map[string]any{
+		"serviceOfferingName": offering,
+		"servicePlanName":     plan,
+	})
+}
+
+func serviceBindingCreate(params api.ToolHandlerParams) (*api.ToolCallResult, error) {
+	args := params.GetArguments()
+	instance, err := common.GetRequiredString(args, "instance")
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	spec := map[string]any{"serviceInstanceName": instance}
+	secretName, err := common.GetOptionalString(args, "secretName")
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	if secretName != "" {
+		spec["secretName"] = secretName
+	}
+	return createService(params, serviceBindingKind, spec)
+}
+
+// createService creates the ServiceInstance or ServiceBinding with the spec and the common name, externalName and parameters arguments.
+func createService(params api.ToolHandlerParams, kind string, spec map[string]any) (*api.ToolCallResult, error) {
+	args := params.GetArguments()
+	namespace, err := common.GetOptionalString(args, "namespace")
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	name, err := common.GetRequiredString(args, "name")
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	externalName, err := common.GetOptionalString(args, "externalName")
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	parameters, err := getParameters(args)
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	dryRun, err := common.GetOptionalBool(args, "dryRun", false)
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	if externalName != "" {
+		spec["externalName"] = externalName
+	}
+	if len(parameters) > 0 {
+		spec["parameters"] = parameters
+	}
+
+	gvk, err := common.ResolveGroupVersionKind(params.DiscoveryClient(), servicesGroup, kind)
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	resource := &unstructured.Unstructured{Object: map[string]any{"spec": spec}}
+	resource.SetGroupVersionKind(gvk)
+	resource.SetNamespace(params.NamespaceOrDefault(namespace))
+	resource.SetName(name)
+
+	created, err := createResource(params, gvk, resource, dryRun)
+	if err != nil {
+		mcplog.HandleK8sError(params.Context, err, "service resource create")
+		if apierrors.IsAlreadyExists(err) {
+			return api.NewToolCallResult("", fmt.Errorf("%s %s/%s already exists: %w", kind, resource.GetNamespace(), name, err)), nil
+		}
+		return api.NewToolCallResult("", fmt.Errorf("failed to create %s: %w", kind, err)), nil
+	}
+	marshalled, err := output.MarshalYaml(created)
+	if err != nil {
+		return api.NewToolCallResult("", fmt.Errorf("failed to marshal %s: %w", kind, err)), nil
+	}
+	header := "# The following " + kind + " (YAML) has been created, it is provisioned asynchronously by the btp-operator\n"
+	if dryRun {
+		header = "# Dry run (not created): the following " + kind + " (YAML) has been validated\n"
+	}
+	return api.NewToolCallResult(header+marshalled, nil), nil
+}
+
+func createResource(params api.ToolHandlerParams, gvk schema.GroupVersionKind, resource *unstructured.Unstructured, dryRun bool) (*unstructured.Unstructured, error) {
+	mapping, err := params.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, err
+	}
+	options := metav1.CreateOptions{}
+	if dryRun {
+		options.DryRun = []string{metav1.DryRunAll}
+	}
+	return params.DynamicClient().Resource(mapping.Resource).Namespace(resource.GetNamespace()).Create(params.Context, resource, options)
+}
+
+// getParameters returns the parameters argument, provided either as an object or as a JSON string.
+func getParameters(args map[string]any) (map[string]any, error) {
+	switch parameters := args["parameters"].(type) {
+	case nil:
+		return nil, nil
+	case map[string]any:
+		return parameters, nil
+	case string:
+		if parameters == "" {
+			return nil, nil
+		}
+		var ret map[string]any
+		if err := json.Unmarshal([]byte(parameters), &ret); err != nil {
+			return nil, fmt.Errorf("parameters is not a valid JSON object: %w", err)
+		}
+		return ret, nil
+	default:
+		return nil, fmt.Errorf("parameters is not an object")
+	}
+}
@@ -0,0 +1,404 @@
+package btp
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/containers/kubernetes-mcp-server/pkg/api"
+	"github.com/containers/kubernetes-mcp-server/pkg/kubernetes"
+	"github.com/containers/kubernetes-mcp-server/pkg/mcplog"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/mfaizanse/ext-kyma-mcp/pkg/toolsets/common"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	servicesGroup = "services.cloud.sap.com"
+
+	serviceInstanceKind = "ServiceInstance"
+	serviceBindingKind  = "ServiceBinding"
+
+	conditionReady     = "Ready"
+	conditionSucceeded = "Succeeded"
+	conditionFailed    = "Failed"
+)
+
+func initServices() []api.ServerTool {
+	namespaceProperties := func(resources string) map[string]*jsonschema.Schema {
+		return map[string]*jsonschema.Schema{
+			"namespace": {
+				Type:        "string",
+				Description: "Namespace of the " + resources + " (optional, lists the " + resources + " of all namespaces if not provided)",
+			},
+		}
+	}
+	return []api.ServerTool{
+		{
+			Tool: api.Tool{
+				Name:        "btp_service_instances_list",
+				Description: "List the SAP BTP ServiceInstances with their offering, plan, ready state and last error message",
+				InputSchema: &jsonschema.Schema{
+					Type:       "object",
+					Properties: namespaceProperties("ServiceInstances"),
+				},
+				Annotations: api.ToolAnnotations{
+					Title:           "BTP: Service Instances List",
+					ReadOnlyHint:    ptr.To(true),
+					DestructiveHint: ptr.To(false),
+					OpenWorldHint:   ptr.To(true),
+				},
+			},
+			Handler: serviceInstancesList,
+		},
+		{
+			Tool: api.Tool{
+				Name:        "btp_service_bindings_list",
+				Description: "List the SAP BTP ServiceBindings with their ServiceInstance, binding Secret, ready state, last error message and the Deployments mounting the binding Secret",
+				InputSchema: &jsonschema.Schema{
+					Type:       "object",
+					Properties: namespaceProperties("ServiceBindings"),
+				},
+				Annotations: api.ToolAnnotations{
+					Title:           "BTP: Service Bindings List",
+					ReadOnlyHint:    ptr.To(true),
+					DestructiveHint: ptr.To(false),
+					OpenWorldHint:   ptr.To(true),
+				},
+			},
+			Handler: serviceBindingsList,
+		},
+		{
+			Tool: api.Tool{
+				Name: "btp_service_binding_inspect",
+				Description: "Inspect a SAP BTP ServiceBinding: its conditions, its ServiceInstance state, the keys (never the values) of the generated binding Secret, " +
+					"the Deployments mounting the binding Secret and the binding events",
+				InputSchema: &jsonschema.Schema{
+					Type: "object",
+					Properties: map[string]*jsonschema.Schema{
+						"namespace": {
+							Type:        "string",
+							Description: "Namespace of the ServiceBinding (optional, defaults to the configured namespace)",
+						},
+						"name": {
+							Type:        "string",
+							Description: "Name of the ServiceBinding",
+						},
+					},
+					Required: []string{"name"},
+				},
+				Annotations: api.ToolAnnotations{
+					Title:           "BTP: Service Binding Inspect",
+					ReadOnlyHint:    ptr.To(true),
+					DestructiveHint: ptr.To(false),
+					OpenWorldHint:   ptr.To(true),
+				},
+			},
+			Handler: serviceBindingInspect,
+		},
+	}
+}
+
+func listSorted(params api.ToolHandlerParams, kind, namespace string) ([]unstructured.Unstructured, error) {
+	gvk, err := common.ResolveGroupVersionKind(params.DiscoveryClient(), servicesGroup, kind)
+	if err != nil {
+		return nil, err
+	}
+	ret, err := common.ListResources(params, gvk, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %ss: %w", kind, err)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].GetNamespace() != ret[j].GetNamespace() {
+			return ret[i].GetNamespace() < ret[j].GetNamespace()
+		}
+		return ret[i].GetName() < ret[j].GetName()
+	})
+	return ret, nil
+}
+
+func serviceInstancesList(params api.ToolHandlerParams) (*api.ToolCallResult, error) {
+	namespace, err := common.GetOptionalString(params.GetArguments(), "namespace")
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	instances, err := listSorted(params, serviceInstanceKind, namespace)
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	if len(instances) == 0 {
+		return api.NewToolCallResult("# No ServiceInstances found", nil), nil
+	}
+
+	buf := new(bytes.Buffer)
+	w := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "NAMESPACE\tNAME\tOFFERING\tPLAN\tREADY\tOPERATION")
+	var lastErrors []string
+	for i := range instances {
+		instance := &instances[i]
+		offering, _, _ := unstructured.NestedString(instance.Object, "spec", "serviceOfferingName")
+		plan, _, _ := unstructured.NestedString(instance.Object, "spec", "servicePlanName")
+		operation, _, _ := unstructured.NestedString(instance.Object, "status", "operationType")
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			instance.GetNamespace(), instance.GetName(), common.ValueOrDash(offering), common.ValueOrDash(plan),
+			readyState(instance), common.ValueOrDash(operation))
+		if lastError := lastErrorMessage(instance); lastError != "" {
+			lastErrors = append(lastErrors, fmt.Sprintf("- %s/%s: %s", instance.GetNamespace(), instance.GetName(), lastError))
+		}
+	}
+	_ = w.Flush()
+
+	ret := strings.TrimSpace(buf.String())
+	if len(lastErrors) > 0 {
+		ret += "\n\n# Last error messages\n" + strings.Join(lastErrors, "\n")
+	}
+	return api.NewToolCallResult(ret, nil), nil
+}
+
+func serviceBindingsList(params api.ToolHandlerParams) (*api.ToolCallResult, error) {
+	namespace, err := common.GetOptionalString(params.GetArguments(), "namespace")
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	bindings, err := listSorted(params, serviceBindingKind, namespace)
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	if len(bindings) == 0 {
+		return api.NewToolCallResult("# No ServiceBindings found", nil), nil
+	}
+
+	// the Deployments are listed once, an error only hides the correlation
+	deployments, deploymentsErr := params.AppsV1().Deployments(namespace).List(params.Context, metav1.ListOptions{})
+	if deploymentsErr != nil {
+		mcplog.HandleK8sError(params.Context, deploymentsErr, "deployments listing")
+		deployments = &appsv1.DeploymentList{}
+	}
+
+	buf := new(bytes.Buffer)
+	w := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "NAMESPACE\tNAME\tINSTANCE\tSECRET\tREADY\tDEPLOYMENTS")
+	var lastErrors []string
+	for i := range bindings {
+		binding := &bindings[i]
+		instance, _, _ := unstructured.NestedString(binding.Object, "spec", "serviceInstanceName")
+		secretName := bindingSecretName(binding)
+		var consumers []string
+		for _, consumer := range secretConsumers(deployments.Items, binding.GetNamespace(), secretName) {
+			consumers = append(consumers, consumer.deployment)
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			binding.GetNamespace(), binding.GetName(), common.ValueOrDash(instance), secretName,
+			readyState(binding), common.ValueOrDash(strings.Join(consumers, ",")))
+		if lastError := lastErrorMessage(binding); lastError != "" {
+			lastErrors = append(lastErrors, fmt.Sprintf("- %s/%s: %s", binding.GetNamespace(), binding.GetName(), lastError))
+		}
+	}
+	_ = w.Flush()
+
+	ret := strings.TrimSpace(buf.String())
+	if len(lastErrors) > 0 {
+		ret += "\n\n# Last error messages (inspect them with btp_service_binding_inspect)\n" + strings.Join(lastErrors, "\n")
+	}
+	if deploymentsErr != nil {
+		ret += fmt.Sprintf("\n\n# Deployments unavailable: %v", deploymentsErr)
+	}
+	return api.NewToolCallResult(ret, nil), nil
+}
+
+func serviceBindingInspect(params api.ToolHandlerParams) (*api.ToolCallResult, error) {
+	args := params.GetArguments()
+	namespace, err := common.GetOptionalString(args, "namespace")
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	name, err := common.GetRequiredString(args, "name")
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	bindingGVK, err := common.ResolveGroupVersionKind(params.DiscoveryClient(), servicesGroup, serviceBindingKind)
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+
+	core := kubernetes.NewCore(params)
+	namespace = core.NamespaceOrDefault(namespace)
+	binding, err := core.ResourcesGet(params.Context, &bindingGVK, namespace, name)
+	if err != nil {
+		mcplog.HandleK8sError(params.Context, err, "servicebinding access")
+		return api.NewToolCallResult("", fmt.Errorf("failed to get ServiceBinding: %w", err)), nil
+	}
+
+	instanceName, _, _ := unstructured.NestedString(binding.Object, "spec", "serviceInstanceName")
+	secretName := bindingSecretName(binding)
+	lines := []string{
+		fmt.Sprintf("# ServiceBinding %s/%s", namespace, name),
+		"- ready: " + readyState(binding),
+		"- last error: " + common.ValueOrDash(lastErrorMessage(binding)),
+	}
+	lines = append(lines, conditionLines(binding)...)
+
+	lines = append(lines, "", "## ServiceInstance "+common.ValueOrDash(instanceName))
+	if instanceGVK, err := common.ResolveGroupVersionKind(params.DiscoveryClient(), servicesGroup, serviceInstanceKind); err != nil {
+		lines = append(lines, fmt.Sprintf("- unavailable: %v", err))
+	} else if instance, err := core.ResourcesGet(params.Context, &instanceGVK, namespace, instanceName); err != nil {
+		mcplog.HandleK8sError(params.Context, err, "serviceinstance access")
+		lines = append(lines, fmt.Sprintf("- unavailable: %v", err))
+		if apierrors.IsNotFound(err) {
+			lines = append(lines, "- the binding cannot succeed until the ServiceInstance exists in the same namespace")
+		}
+	} else {
+		offering, _, _ := unstructured.NestedString(instance.Object, "spec", "serviceOfferingName")
+		plan, _, _ := unstructured.NestedString(instance.Object, "spec", "servicePlanName")
+		lines = append(lines,
+			fmt.Sprintf("- offering: %s, plan: %s", common.ValueOrDash(offering), common.ValueOrDash(plan)),
+			"- ready: "+readyState(instance),
+			"- last error: "+common.ValueOrDash(lastErrorMessage(instance)))
+		if readyState(instance) != string(metav1.ConditionTrue) {
+			lines = append(lines, "- the binding cannot succeed until the ServiceInstance is ready")
+		}
+	}
+
+	lines = append(lines, "", fmt.Sprintf("## Binding Secret %s/%s (keys only)", namespace, secretName))
+	secret, err := params.CoreV1().Secrets(namespace).Get(params.Context, secretName, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		lines = append(lines, "- not created yet, the Secret is created once the binding succeeds")
+	case err != nil:
+		mcplog.HandleK8sError(params.Context, err, "secret access")
+		lines = append(lines, fmt.Sprintf("- unavailable: %v", err))
+	default:
+		lines = append(lines, "- keys: "+common.ValueOrDash(strings.Join(secretKeys(secret), ", ")))
+	}
+
+	lines = append(lines, "", "## Deployments mounting the binding Secret")
+	deployments, err := params.AppsV1().Deployments(namespace).List(params.Context, metav1.ListOptions{})
+	if err != nil {
+		mcplog.HandleK8sError(params.Context, err, "deployments listing")
+		lines = append(lines, fmt.Sprintf("- unavailable: %v", err))
+	} else if consumers := secretConsumers(deployments.Items, namespace, secretName); len(consumers) == 0 {
+		lines = append(lines, "- none")
+	} else {
+		for _, consumer := range consumers {
+			lines = append(lines, fmt.Sprintf("- %s (%s)", consumer.deployment, strings.Join(consumer.usages, ", ")))
+		}
+	}
+
+	events, err := common.ListEventsForResource(params, namespace, serviceBindingKind, name)
+	if err != nil {
+		events = fmt.Sprintf("# Events unavailable: %v", err)
+	}
+	lines = append(lines, "", "## Events (YAML)", events)
+	return api.NewToolCallResult(strings.Join(lines, "\n"), nil), nil
+}
+
+// bindingSecretName returns the name of the binding Secret, which defaults to the binding name.
+func bindingSecretName(binding *unstructured.Unstructured) string {
+	if secretName, _, _ := unstructured.NestedString(binding.Object, "spec", "secretName"); secretName != "" {
+		return secretName
+	}
+	return binding.GetName()
+}
+
+// readyState returns the Ready condition status, or the status.ready field of older btp-operator versions.
+func readyState(resource *unstructured.Unstructured) string {
+	if ready := common.GetConditions(resource).Status(conditionReady); ready != "-" {
+		return ready
+	}
+	ready, _, _ := unstructured.NestedString(resource.Object, "status", "ready")
+	return common.ValueOrDash(ready)
+}
+
+// lastErrorMessage returns the message of the Failed condition, or of the not succeeded or not ready conditions.
+func lastErrorMessage(resource *unstructured.Unstructured) string {
+	conditions := common.GetConditions(resource)
+	for _, condition := range conditions {
+		if condition.Type == conditionFailed && condition.Status == string(metav1.ConditionTrue) && condition.Message != "" {
+			return condition.Message
+		}
+	}
+	for _, conditionType := range []string{conditionSucceeded, conditionReady} {
+		for _, condition := range conditions {
+			if condition.Type == conditionType && condition.Status == string(metav1.ConditionFalse) && condition.Message != "" {
+				return condition.Message
+			}
+		}
+	}
+	return ""
+}
+
+func conditionLines(resource *unstructured.Unstructured) []string {
+	var ret []string
+	for _, condition := range common.GetConditions(resource) {
+		ret = append(ret, fmt.Sprintf("- condition %s=%s (%s): %s",
+			condition.Type, condition.Status, common.ValueOrDash(condition.Reason), common.ValueOrDash(condition.Message)))
+	}
+	return ret
+}
+
+func secretKeys(secret *v1.Secret) []string {
+	ret := make([]string, 0, len(secret.Data))
+	for key := range secret.Data {
+		ret = append(ret, key)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+type secretConsumer struct {
+	deployment string
+	usages     []string
+}
+
+// secretConsumers returns the Deployments of the namespace mounting the Secret as a volume or referencing it in their environment.
+func secretConsumers(deployments []appsv1.Deployment, namespace, secretName string) []secretConsumer {
+	var ret []secretConsumer
+	for _, deployment := range deployments {
+		if deployment.Namespace != namespace {
+			continue
+		}
+		if usages := podSecretUsages(&deployment.Spec.Template.Spec, secretName); len(usages) > 0 {
+			ret = append(ret, secretConsumer{deployment: deployment.Name, usages: usages})
+		}
+	}
+	return ret
+}
+
+func podSecretUsages(spec *v1.PodSpec, secretName string) []string {
+	var ret []string
+	for _, volume := range spec.Volumes {
+		if volume.Secret != nil && volume.Secret.SecretName == secretName {
+			ret = append(ret, "volume "+volume.Name)
+		}
+		if volume.Projected != nil {
+			for _, source := range volume.Projected.Sources {
+				if source.Secret != nil && source.Secret.Name == secretName {
+					ret = append(ret, "projected volume "+volume.Name)
+				}
+			}
+		}
+	}
+	containers := make([]v1.Container, 0, len(spec.InitContainers)+len(spec.Containers))
+	containers = append(containers, spec.InitContainers...)
+	containers = append(containers, spec.Containers...)
+	for _, container := range containers {
+		for _, envFrom := range container.EnvFrom {
+			if envFrom.SecretRef != nil && envFrom.SecretRef.Name == secretName {
+				ret = append(ret, "envFrom in container "+container.Name)
+			}
+		}
+		for _, env := range container.Env {
+			if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil && env.ValueFrom.SecretKeyRef.Name == secretName {
+				ret = append(ret, fmt.Sprintf("env %s in container %s", env.Name, container.Name))
+			}
+		}
+	}
+	return ret
+}
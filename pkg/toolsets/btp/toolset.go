@@ -0,0 +1,32 @@
+package btp
+
+import (
+	"slices"
+
+	"github.com/containers/kubernetes-mcp-server/pkg/api"
+	"github.com/containers/kubernetes-mcp-server/pkg/toolsets"
+)
+
+type Toolset struct{}
+
+var _ api.Toolset = (*Toolset)(nil)
+
+func (t *Toolset) GetName() string {
+	return "btp"
+}
+
+func (t *Toolset) GetDescription() string {
+	return "SAP BTP service operator tools for managing and troubleshooting ServiceInstances and ServiceBindings"
+}
+
+func (t *Toolset) GetTools(api.Openshift) []api.ServerTool {
+	return slices.Concat(initServices(), initServiceCreate())
+}
+
+func (t *Toolset) GetPrompts() []api.ServerPrompt {
+	return nil
+}
+
+func init() {
+	toolsets.Register(&Toolset{})
+}
@@ -82,3 +82,36 @@ func GetOptionalBool(args map[string]any, key string, defaultValue bool) (bool,
 	}
 	return boolValue, nil
 }
+
+func GetOptionalStringArray(args map[string]any, key string, defaultValue []string) ([]string, error) {
+	value, ok := args[key]
+	if !ok || value == nil {
+		return defaultValue, nil
+	}
+	var items []any
+	switch typed := value.(type) {
+	case []string:
+		items = make([]any, len(typed))
+		for i, item := range typed {
+			items[i] = item
+		}
+	case []any:
+		items = typed
+	default:
+		return nil, fmt.Errorf("%s is not an array of strings", key)
+	}
+	ret := make([]string, 0, len(items))
+	for _, item := range items {
+		strValue, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s is not an array of strings", key)
+		}
+		if trimmed := strings.TrimSpace(strValue); trimmed != "" {
+			ret = append(ret, trimmed)
+		}
+	}
+	if len(ret) == 0 {
+		return defaultValue, nil
+	}
+	return ret, nil
+}
@@ -0,0 +1,45 @@
+package common
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Condition is a status condition of a Kyma custom resource.
+type Condition struct {
+	Type    string
+	Status  string
+	Reason  string
+	Message string
+}
+
+// Conditions are the status conditions of a Kyma custom resource.
+type Conditions []Condition
+
+// Status returns the status of the condition type, or a dash if the condition is not reported.
+func (c Conditions) Status(conditionType string) string {
+	for _, condition := range c {
+		if condition.Type == conditionType {
+			return condition.Status
+		}
+	}
+	return "-"
+}
+
+// GetConditions returns the status.conditions of the resource.
+func GetConditions(resource *unstructured.Unstructured) Conditions {
+	statusConditions, _, _ := unstructured.NestedSlice(resource.Object, "status", "conditions")
+	ret := make(Conditions, 0, len(statusConditions))
+	for _, statusCondition := range statusConditions {
+		conditionMap, ok := statusCondition.(map[string]any)
+		if !ok {
+			continue
+		}
+		c := Condition{}
+		c.Type, _ = conditionMap["type"].(string)
+		c.Status, _ = conditionMap["status"].(string)
+		c.Reason, _ = conditionMap["reason"].(string)
+		c.Message, _ = conditionMap["message"].(string)
+		ret = append(ret, c)
+	}
+	return ret
+}
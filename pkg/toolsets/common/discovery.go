@@ -0,0 +1,173 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+// CoreGroup selects the core ("") API group in the group filter of FindResources.
+const CoreGroup = "core"
+
+// ResourceMatch is a resource of a kind served in a group version.
+type ResourceMatch struct {
+	GroupVersion schema.GroupVersion
+	Kind         string
+	// Resource is the plural resource name, e.g. functions.
+	Resource   string
+	Namespaced bool
+	ShortNames []string
+	Verbs      []string
+	// Preferred is true for the version of the group served in the preferred group version, or in the first version
+	// of the group serving the kind otherwise (as kubectl does).
+	Preferred bool
+}
+
+// Scope returns the scope of the resource, Namespaced or Cluster.
+func (m ResourceMatch) Scope() string {
+	if m.Namespaced {
+		return "Namespaced"
+	}
+	return "Cluster"
+}
+
+// FindResources finds all the served resources of the kind (case-insensitive) in all the versions of the groups,
+// optionally filtered by group (CoreGroup for the core group). The matches are sorted in the discovery order of the
+// groups and of their versions.
+// The group versions whose discovery failed are returned with the matches found in the other group versions.
+func FindResources(client discovery.DiscoveryInterface, group, kind string) ([]ResourceMatch, map[schema.GroupVersion]error, error) {
+	groups, resourceLists, err := discovery.ServerGroupsAndResources(client)
+	var failed map[schema.GroupVersion]error
+	if err != nil {
+		var groupErr *discovery.ErrGroupDiscoveryFailed
+		if !errors.As(err, &groupErr) {
+			return nil, nil, fmt.Errorf("failed to discover resources: %w", err)
+		}
+		failed = groupErr.Groups
+	}
+	// an empty group disables the filter, CoreGroup selects the core ("") group
+	filtered := group != ""
+	if group == CoreGroup {
+		group = ""
+	}
+
+	// the discovery order and the preferred version of each group
+	groupOrder := make(map[string]int)
+	versionOrder := make(map[schema.GroupVersion]int)
+	preferred := make(map[string]string)
+	for i, apiGroup := range groups {
+		groupOrder[apiGroup.Name] = i
+		preferred[apiGroup.Name] = apiGroup.PreferredVersion.Version
+		for j, version := range apiGroup.Versions {
+			versionOrder[schema.GroupVersion{Group: apiGroup.Name, Version: version.Version}] = j
+		}
+	}
+
+	var ret []ResourceMatch
+	for _, list := range resourceLists {
+		gv, parseErr := schema.ParseGroupVersion(list.GroupVersion)
+		if parseErr != nil || (filtered && gv.Group != group) {
+			continue
+		}
+		for _, resource := range list.APIResources {
+			if strings.Contains(resource.Name, "/") || !strings.EqualFold(resource.Kind, kind) {
+				continue
+			}
+			ret = append(ret, ResourceMatch{
+				GroupVersion: gv,
+				Kind:         resource.Kind,
+				Resource:     resource.Name,
+				Namespaced:   resource.Namespaced,
+				ShortNames:   resource.ShortNames,
+				Verbs:        resource.Verbs,
+			})
+		}
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		if ret[i].GroupVersion.Group != ret[j].GroupVersion.Group {
+			return groupOrder[ret[i].GroupVersion.Group] < groupOrder[ret[j].GroupVersion.Group]
+		}
+		return versionOrder[ret[i].GroupVersion] < versionOrder[ret[j].GroupVersion]
+	})
+
+	for i := range ret {
+		groupKind := func(match ResourceMatch) bool {
+			return match.GroupVersion.Group == ret[i].GroupVersion.Group && match.Kind == ret[i].Kind
+		}
+		preferredIdx := slices.IndexFunc(ret, func(match ResourceMatch) bool {
+			return groupKind(match) && match.GroupVersion.Version == preferred[match.GroupVersion.Group]
+		})
+		if preferredIdx < 0 {
+			preferredIdx = slices.IndexFunc(ret, groupKind)
+		}
+		ret[i].Preferred = preferredIdx == i
+	}
+	return ret, failed, nil
+}
+
+// ResolveResourceVersion finds the preferred apiVersion for the provided resource kind.
+// Returns the first matched result from discovery.
+func ResolveResourceVersion(client discovery.DiscoveryInterface, kind string) (string, error) {
+	return resolvePreferredVersion(client, "", kind)
+}
+
+// ResolveGroupResourceVersion finds the preferred apiVersion for the provided resource kind of the API group.
+// Unlike ResolveResourceVersion, kinds with the same name in other groups (e.g. the Istio and the Gateway API
+// Gateway) are not matched.
+func ResolveGroupResourceVersion(client discovery.DiscoveryInterface, group, kind string) (string, error) {
+	if group == "" {
+		group = CoreGroup
+	}
+	return resolvePreferredVersion(client, group, kind)
+}
+
+// ResolveGroupVersionKind resolves the kind of the API group in its preferred served version, see
+// ResolveGroupResourceVersion.
+func ResolveGroupVersionKind(client discovery.DiscoveryInterface, group, kind string) (schema.GroupVersionKind, error) {
+	apiVersion, err := ResolveGroupResourceVersion(client, group, kind)
+	if err != nil {
+		return schema.GroupVersionKind{}, fmt.Errorf("%s is not served, is the module providing it enabled? %w", kind, err)
+	}
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return schema.GroupVersionKind{}, fmt.Errorf("invalid %s apiVersion %s: %w", kind, apiVersion, err)
+	}
+	return gv.WithKind(kind), nil
+}
+
+func resolvePreferredVersion(client discovery.DiscoveryInterface, group, kind string) (string, error) {
+	matches, failed, err := FindResources(client, group, kind)
+	if err != nil {
+		return "", err
+	}
+	for _, match := range matches {
+		if match.Preferred {
+			return match.GroupVersion.String(), nil
+		}
+	}
+
+	notFound := fmt.Errorf("resource kind not found: %s", kind)
+	if group != "" {
+		notFound = fmt.Errorf("resource kind not found in group %s: %s", group, kind)
+	}
+	if len(failed) > 0 {
+		// the kind may be served by a group version whose discovery failed
+		return "", fmt.Errorf("%w (discovery failed for %s)", notFound, strings.Join(DiscoveryFailures(failed), "; "))
+	}
+	return "", notFound
+}
+
+// DiscoveryFailures describes the group versions whose discovery failed, sorted by group version.
+func DiscoveryFailures(failed map[schema.GroupVersion]error) []string {
+	ret := make([]string, 0, len(failed))
+	for gv, err := range failed {
+		ret = append(ret, fmt.Sprintf("%s: %v", gv, err))
+	}
+	sort.Strings(ret)
+	return ret
+}
@@ -0,0 +1,60 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/containers/kubernetes-mcp-server/pkg/api"
+	"github.com/containers/kubernetes-mcp-server/pkg/kubernetes"
+	"github.com/containers/kubernetes-mcp-server/pkg/mcplog"
+	"github.com/containers/kubernetes-mcp-server/pkg/output"
+)
+
+// ListWarningEvents lists the warning events of the namespace (all namespaces if empty) as YAML.
+// If predicate is not nil, only the warning events it accepts are listed.
+func ListWarningEvents(params api.ToolHandlerParams, namespace string, predicate func(map[string]any) bool) (string, error) {
+	events, err := kubernetes.NewCore(params).EventsList(params.Context, namespace)
+	if err != nil {
+		mcplog.HandleK8sError(params.Context, err, "events listing")
+		return "", fmt.Errorf("failed to list events: %w", err)
+	}
+	filtered := FilterEvents(events, func(event map[string]any) bool {
+		value, ok := event["Type"].(string)
+		return ok && strings.EqualFold(value, "Warning") && (predicate == nil || predicate(event))
+	})
+	if len(filtered) == 0 {
+		return "# No warning events found", nil
+	}
+	yamlEvents, err := output.MarshalYaml(filtered)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal warning events: %w", err)
+	}
+	return strings.TrimSpace(yamlEvents), nil
+}
+
+// ListEventsForResource lists the events of the resource as YAML.
+func ListEventsForResource(params api.ToolHandlerParams, namespace, kind, name string) (string, error) {
+	events, err := kubernetes.NewCore(params).EventsList(params.Context, namespace)
+	if err != nil {
+		mcplog.HandleK8sError(params.Context, err, "events listing")
+		return "", fmt.Errorf("failed to list events: %w", err)
+	}
+	filtered := FilterEvents(events, func(event map[string]any) bool {
+		involved, ok := EventInvolvedObject(event)
+		return ok && strings.EqualFold(involved["Kind"], kind) && strings.EqualFold(involved["Name"], name)
+	})
+	if len(filtered) == 0 {
+		return "# No events found for resource", nil
+	}
+	yamlEvents, err := output.MarshalYaml(filtered)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal resource events: %w", err)
+	}
+	return strings.TrimSpace(yamlEvents), nil
+}
+
+// EventInvolvedObject returns the involved object (apiVersion, Kind and Name) of an event map.
+func EventInvolvedObject(event map[string]any) (map[string]string, bool) {
+	involved, ok := event["InvolvedObject"].(map[string]string)
+	return involved, ok
+}
@@ -0,0 +1,40 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/containers/kubernetes-mcp-server/pkg/api"
+	"github.com/containers/kubernetes-mcp-server/pkg/kubernetes"
+	"github.com/containers/kubernetes-mcp-server/pkg/mcplog"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ListResources lists the resources of the kind in the namespace (all namespaces if empty).
+func ListResources(params api.ToolHandlerParams, gvk schema.GroupVersionKind, namespace string) ([]unstructured.Unstructured, error) {
+	return ListResourcesWithOptions(params, gvk, namespace, api.ListOptions{})
+}
+
+// ListResourcesWithOptions lists the resources of the kind in the namespace (all namespaces if empty)
+// matching the list options (e.g. a label selector).
+func ListResourcesWithOptions(params api.ToolHandlerParams, gvk schema.GroupVersionKind, namespace string, options api.ListOptions) ([]unstructured.Unstructured, error) {
+	list, err := kubernetes.NewCore(params).ResourcesList(params.Context, &gvk, namespace, options)
+	if err != nil {
+		mcplog.HandleK8sError(params.Context, err, strings.ToLower(gvk.Kind)+" listing")
+		return nil, err
+	}
+	unstructuredList, ok := list.(*unstructured.UnstructuredList)
+	if !ok {
+		return nil, fmt.Errorf("unexpected %s list type %T", gvk.Kind, list)
+	}
+	return unstructuredList.Items, nil
+}
+
+// ValueOrDash returns the value, or a dash if it is empty, for table cells.
+func ValueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,68 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/containers/kubernetes-mcp-server/pkg/api"
+	"github.com/containers/kubernetes-mcp-server/pkg/mcplog"
+	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+// ServiceReadyEndpoints returns the number of ready endpoints of the Service, or -1 if they cannot be listed.
+func ServiceReadyEndpoints(params api.ToolHandlerParams, namespace, service string) int {
+	endpointSlices, err := params.DiscoveryV1().EndpointSlices(namespace).List(params.Context, metav1.ListOptions{LabelSelector: discoveryv1.LabelServiceName + "=" + service})
+	if err != nil {
+		mcplog.HandleK8sError(params.Context, err, "endpointslices listing")
+		// unknown, not reported as a problem
+		return -1
+	}
+	ready := 0
+	for _, slice := range endpointSlices.Items {
+		for _, endpoint := range slice.Endpoints {
+			if ptr.Deref(endpoint.Conditions.Ready, true) {
+				ready++
+			}
+		}
+	}
+	return ready
+}
+
+// PodProblem describes why the pod is not ready, or returns an empty string for ready pods.
+func PodProblem(pod *v1.Pod) string {
+	if pod.Status.Phase == v1.PodSucceeded {
+		return ""
+	}
+	var problems []string
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Ready {
+			continue
+		}
+		problem := "container " + status.Name + " not ready"
+		if status.State.Waiting != nil && status.State.Waiting.Reason != "" {
+			problem += ", waiting: " + status.State.Waiting.Reason
+			if status.State.Waiting.Message != "" {
+				problem += " (" + status.State.Waiting.Message + ")"
+			}
+		}
+		if status.LastTerminationState.Terminated != nil {
+			problem += fmt.Sprintf(", last terminated: %s (exit code %d)", status.LastTerminationState.Terminated.Reason, status.LastTerminationState.Terminated.ExitCode)
+		}
+		if status.RestartCount > 0 {
+			problem += fmt.Sprintf(", %d restarts", status.RestartCount)
+		}
+		problems = append(problems, problem)
+	}
+	if len(problems) == 0 && pod.Status.Phase != v1.PodRunning {
+		problems = append(problems, "phase "+string(pod.Status.Phase))
+		for _, condition := range pod.Status.Conditions {
+			if condition.Status == v1.ConditionFalse && condition.Message != "" {
+				problems = append(problems, condition.Message)
+			}
+		}
+	}
+	return strings.Join(problems, "; ")
+}
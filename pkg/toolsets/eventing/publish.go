@@ -0,0 +1,113 @@
+package eventing
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/containers/kubernetes-mcp-server/pkg/api"
+	"github.com/containers/kubernetes-mcp-server/pkg/mcplog"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/mfaizanse/ext-kyma-mcp/pkg/toolsets/common"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	cloudEventsContentType = "application/cloudevents+json"
+	publisherProxyPort     = 80
+)
+
+func initPublish() []api.ServerTool {
+	return []api.ServerTool{
+		{
+			Tool: api.Tool{
+				Name: "eventing_event_publish",
+				Description: "Publish a test CloudEvent through the Kyma eventing publisher proxy from inside the cluster (through the API server service proxy), " +
+					"to check the delivery to the Subscriptions of the event type and source. " +
+					"The event is delivered to every live subscriber of the type, with the side effects of a real event",
+				InputSchema: &jsonschema.Schema{
+					Type: "object",
+					Properties: map[string]*jsonschema.Schema{
+						"type": {
+							Type:        "string",
+							Description: "Type of the event, as in the Subscription spec.types (e.g. order.created.v1)",
+						},
+						"source": {
+							Type:        "string",
+							Description: "Source of the event, as in the Subscription spec.source (e.g. myapp)",
+						},
+						"data": {
+							Type:        "object",
+							Description: "JSON data of the event (optional, defaults to an empty object)",
+						},
+						"id": {
+							Type:        "string",
+							Description: "ID of the event (optional, defaults to a random UUID)",
+						},
+					},
+					Required: []string{"type", "source"},
+				},
+				Annotations: api.ToolAnnotations{
+					Title:           "Eventing: Event Publish",
+					ReadOnlyHint:    ptr.To(false),
+					DestructiveHint: ptr.To(true),
+					IdempotentHint:  ptr.To(false),
+					OpenWorldHint:   ptr.To(true),
+				},
+			},
+			Handler: eventPublish,
+		},
+	}
+}
+
+func eventPublish(params api.ToolHandlerParams) (*api.ToolCallResult, error) {
+	args := params.GetArguments()
+	eventType, err := common.GetRequiredString(args, "type")
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	source, err := common.GetRequiredString(args, "source")
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	id, err := common.GetOptionalStringDefault(args, "id", string(uuid.NewUUID()))
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	data, ok := args["data"]
+	if !ok || data == nil {
+		data = map[string]any{}
+	}
+
+	event, err := json.Marshal(map[string]any{
+		"specversion":     "1.0",
+		"id":              id,
+		"type":            eventType,
+		"source":          source,
+		"datacontenttype": "application/json",
+		"data":            data,
+	})
+	if err != nil {
+		return api.NewToolCallResult("", fmt.Errorf("failed to marshal the CloudEvent: %w", err)), nil
+	}
+
+	// the publisher proxy is only exposed in the cluster, the API server proxies the request to its Service
+	result := params.CoreV1().RESTClient().Post().
+		Namespace(kymaNamespace).
+		Resource("services").
+		Name(publisherProxyName+":"+strconv.Itoa(publisherProxyPort)).
+		SubResource("proxy", "publish").
+		SetHeader("Content-Type", cloudEventsContentType).
+		Body(event).
+		Do(params.Context)
+	var statusCode int
+	result.StatusCode(&statusCode)
+	if err := result.Error(); err != nil {
+		mcplog.HandleK8sError(params.Context, err, "publisher proxy access")
+		return api.NewToolCallResult("", fmt.Errorf("failed to publish the event through %s/%s (status %d), check it with eventing_backend_get: %w",
+			kymaNamespace, publisherProxyName, statusCode, err)), nil
+	}
+	return api.NewToolCallResult(fmt.Sprintf("# The event %s of type %s and source %s was accepted by the publisher proxy (status %d)\n"+
+		"Check its delivery in the sink logs, or with eventing_subscription_explain if it is not delivered.", id, eventType, source, statusCode), nil), nil
+}
@@ -0,0 +1,426 @@
+package eventing
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/containers/kubernetes-mcp-server/pkg/api"
+	"github.com/containers/kubernetes-mcp-server/pkg/kubernetes"
+	"github.com/containers/kubernetes-mcp-server/pkg/mcplog"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/mfaizanse/ext-kyma-mcp/pkg/toolsets/common"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	eventingGroup    = "eventing.kyma-project.io"
+	operatorGroup    = "operator.kyma-project.io"
+	subscriptionKind = "Subscription"
+	eventingKind     = "Eventing"
+
+	kymaNamespace        = "kyma-system"
+	publisherProxyName   = "eventing-publisher-proxy"
+	defaultTypeMatching  = "standard"
+	clusterServiceSuffix = "svc.cluster.local"
+)
+
+func initSubscriptions() []api.ServerTool {
+	return []api.ServerTool{
+		{
+			Tool: api.Tool{
+				Name:        "eventing_subscriptions_list",
+				Description: "List the Kyma Eventing Subscriptions with their sink, source, event types, typeMatching and ready state",
+				InputSchema: &jsonschema.Schema{
+					Type: "object",
+					Properties: map[string]*jsonschema.Schema{
+						"namespace": {
+							Type:        "string",
+							Description: "Namespace of the Subscriptions (optional, lists the Subscriptions of all namespaces if not provided)",
+						},
+					},
+				},
+				Annotations: api.ToolAnnotations{
+					Title:           "Eventing: Subscriptions List",
+					ReadOnlyHint:    ptr.To(true),
+					DestructiveHint: ptr.To(false),
+					OpenWorldHint:   ptr.To(true),
+				},
+			},
+			Handler: subscriptionsList,
+		},
+		{
+			Tool: api.Tool{
+				Name:        "eventing_backend_get",
+				Description: "Get the active Kyma Eventing backend (NATS or SAP Event Mesh) from the Eventing CR, with its state, conditions and the eventing publisher proxy status",
+				InputSchema: &jsonschema.Schema{
+					Type:       "object",
+					Properties: map[string]*jsonschema.Schema{},
+				},
+				Annotations: api.ToolAnnotations{
+					Title:           "Eventing: Backend Get",
+					ReadOnlyHint:    ptr.To(true),
+					DestructiveHint: ptr.To(false),
+					OpenWorldHint:   ptr.To(true),
+				},
+			},
+			Handler: backendGet,
+		},
+		{
+			Tool: api.Tool{
+				Name: "eventing_subscription_explain",
+				Description: "Explain why the events of a Kyma Eventing Subscription are not delivered: correlates the Subscription conditions and types, " +
+					"the sink Service and its ready endpoints, the Eventing backend state and the eventing publisher proxy pods",
+				InputSchema: &jsonschema.Schema{
+					Type: "object",
+					Properties: map[string]*jsonschema.Schema{
+						"namespace": {
+							Type:        "string",
+							Description: "Namespace of the Subscription (optional, defaults to the configured namespace)",
+						},
+						"name": {
+							Type:        "string",
+							Description: "Name of the Subscription",
+						},
+					},
+					Required: []string{"name"},
+				},
+				Annotations: api.ToolAnnotations{
+					Title:           "Eventing: Subscription Explain",
+					ReadOnlyHint:    ptr.To(true),
+					DestructiveHint: ptr.To(false),
+					OpenWorldHint:   ptr.To(true),
+				},
+			},
+			Handler: subscriptionExplain,
+		},
+	}
+}
+
+// resolveGVK resolves the served version of the kind of the API group.
+func subscriptionsList(params api.ToolHandlerParams) (*api.ToolCallResult, error) {
+	namespace, err := common.GetOptionalString(params.GetArguments(), "namespace")
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	gvk, err := common.ResolveGroupVersionKind(params.DiscoveryClient(), eventingGroup, subscriptionKind)
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	subscriptions, err := common.ListResources(params, gvk, namespace)
+	if err != nil {
+		return api.NewToolCallResult("", fmt.Errorf("failed to list Subscriptions: %w", err)), nil
+	}
+	if len(subscriptions) == 0 {
+		return api.NewToolCallResult("# No Subscriptions found", nil), nil
+	}
+	sort.Slice(subscriptions, func(i, j int) bool {
+		if subscriptions[i].GetNamespace() != subscriptions[j].GetNamespace() {
+			return subscriptions[i].GetNamespace() < subscriptions[j].GetNamespace()
+		}
+		return subscriptions[i].GetName() < subscriptions[j].GetName()
+	})
+
+	buf := new(bytes.Buffer)
+	w := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "NAMESPACE\tNAME\tSINK\tSOURCE\tTYPES\tTYPE MATCHING\tREADY")
+	var problems []string
+	for i := range subscriptions {
+		subscription := &subscriptions[i]
+		sink, _, _ := unstructured.NestedString(subscription.Object, "spec", "sink")
+		source, _, _ := unstructured.NestedString(subscription.Object, "spec", "source")
+		types, _, _ := unstructured.NestedStringSlice(subscription.Object, "spec", "types")
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			subscription.GetNamespace(), subscription.GetName(), common.ValueOrDash(sink), common.ValueOrDash(source),
+			common.ValueOrDash(strings.Join(types, ",")), typeMatching(subscription), subscriptionReady(subscription))
+		for _, condition := range common.GetConditions(subscription) {
+			if condition.Status == string(metav1.ConditionFalse) && condition.Message != "" {
+				problems = append(problems, fmt.Sprintf("- %s/%s %s (%s): %s",
+					subscription.GetNamespace(), subscription.GetName(), condition.Type, common.ValueOrDash(condition.Reason), condition.Message))
+			}
+		}
+	}
+	_ = w.Flush()
+
+	ret := strings.TrimSpace(buf.String())
+	if len(problems) > 0 {
+		ret += "\n\n# Conditions not ready (explain them with eventing_subscription_explain)\n" + strings.Join(problems, "\n")
+	}
+	return api.NewToolCallResult(ret, nil), nil
+}
+
+func typeMatching(subscription *unstructured.Unstructured) string {
+	if matching, _, _ := unstructured.NestedString(subscription.Object, "spec", "typeMatching"); matching != "" {
+		return matching
+	}
+	return defaultTypeMatching
+}
+
+func subscriptionReady(subscription *unstructured.Unstructured) string {
+	ready, found, _ := unstructured.NestedBool(subscription.Object, "status", "ready")
+	if !found {
+		return "-"
+	}
+	return strconv.FormatBool(ready)
+}
+
+// getEventingCR returns the Eventing CR, which is expected in kyma-system but looked up in all namespaces.
+func getEventingCR(params api.ToolHandlerParams) (*unstructured.Unstructured, error) {
+	gvk, err := common.ResolveGroupVersionKind(params.DiscoveryClient(), operatorGroup, eventingKind)
+	if err != nil {
+		return nil, err
+	}
+	eventings, err := common.ListResources(params, gvk, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Eventing CRs: %w", err)
+	}
+	if len(eventings) == 0 {
+		return nil, fmt.Errorf("no Eventing CR found, is the eventing module enabled?")
+	}
+	for i := range eventings {
+		if eventings[i].GetNamespace() == kymaNamespace {
+			return &eventings[i], nil
+		}
+	}
+	return &eventings[0], nil
+}
+
+// backendLines describes the Eventing CR backend and returns the problems found.
+func backendLines(eventing *unstructured.Unstructured) ([]string, []string) {
+	backendType, _, _ := unstructured.NestedString(eventing.Object, "spec", "backend", "type")
+	activeBackend, _, _ := unstructured.NestedString(eventing.Object, "status", "activeBackend")
+	state, _, _ := unstructured.NestedString(eventing.Object, "status", "state")
+	lines := []string{
+		fmt.Sprintf("- Eventing CR: %s/%s", eventing.GetNamespace(), eventing.GetName()),
+		"- configured backend: " + common.ValueOrDash(backendType),
+		"- active backend: " + common.ValueOrDash(activeBackend),
+		"- state: " + common.ValueOrDash(state),
+	}
+	if secret, _, _ := unstructured.NestedString(eventing.Object, "spec", "backend", "config", "eventMeshSecret"); secret != "" {
+		lines = append(lines, "- Event Mesh Secret: "+secret)
+	}
+	var problems []string
+	switch {
+	case backendType == "" && activeBackend == "":
+		problems = append(problems, "no eventing backend is configured in the Eventing CR spec.backend")
+	case state != "" && state != "Ready":
+		problems = append(problems, fmt.Sprintf("the Eventing CR is in %s state", state))
+	}
+	if backendType != "" && activeBackend != "" && !strings.EqualFold(backendType, activeBackend) {
+		problems = append(problems, fmt.Sprintf("the backend switch from %s to %s is not completed", activeBackend, backendType))
+	}
+	for _, condition := range common.GetConditions(eventing) {
+		lines = append(lines, fmt.Sprintf("- condition %s=%s (%s): %s",
+			condition.Type, condition.Status, common.ValueOrDash(condition.Reason), common.ValueOrDash(condition.Message)))
+		if condition.Status == string(metav1.ConditionFalse) {
+			problems = append(problems, fmt.Sprintf("the Eventing CR condition %s is False: %s", condition.Type, common.ValueOrDash(condition.Message)))
+		}
+	}
+	return lines, problems
+}
+
+// publisherProxyLines describes the eventing publisher proxy Deployment and its non-ready pods, and returns the problems found.
+func publisherProxyLines(params api.ToolHandlerParams) ([]string, []string) {
+	deployment, err := params.AppsV1().Deployments(kymaNamespace).Get(params.Context, publisherProxyName, metav1.GetOptions{})
+	if err != nil {
+		mcplog.HandleK8sError(params.Context, err, "deployment access")
+		return []string{fmt.Sprintf("- unavailable: %v", err)}, []string{fmt.Sprintf("the eventing publisher proxy Deployment cannot be read: %v", err)}
+	}
+	replicas := ptr.Deref(deployment.Spec.Replicas, 1)
+	lines := []string{fmt.Sprintf("- replicas: %d desired, %d ready, %d available", replicas, deployment.Status.ReadyReplicas, deployment.Status.AvailableReplicas)}
+	var problems []string
+	if deployment.Status.AvailableReplicas == 0 {
+		problems = append(problems, "no eventing publisher proxy replica is available, events cannot be published")
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return lines, problems
+	}
+	pods, err := params.CoreV1().Pods(kymaNamespace).List(params.Context, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		mcplog.HandleK8sError(params.Context, err, "pods listing")
+		return append(lines, fmt.Sprintf("- pods unavailable: %v", err)), problems
+	}
+	for _, pod := range pods.Items {
+		if problem := common.PodProblem(&pod); problem != "" {
+			lines = append(lines, fmt.Sprintf("- pod %s: %s", pod.Name, problem))
+			problems = append(problems, fmt.Sprintf("the eventing publisher proxy pod %s is not ready: %s", pod.Name, problem))
+		}
+	}
+	return lines, problems
+}
+
+func backendGet(params api.ToolHandlerParams) (*api.ToolCallResult, error) {
+	eventing, err := getEventingCR(params)
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	lines := []string{"# Eventing backend"}
+	backend, problems := backendLines(eventing)
+	lines = append(lines, backend...)
+	proxy, proxyProblems := publisherProxyLines(params)
+	lines = append(lines, "", fmt.Sprintf("## Publisher proxy %s/%s", kymaNamespace, publisherProxyName))
+	lines = append(lines, proxy...)
+	problems = append(problems, proxyProblems...)
+	if len(problems) > 0 {
+		lines = append(lines, "", "## Problems")
+		for _, problem := range problems {
+			lines = append(lines, "- "+problem)
+		}
+	}
+	return api.NewToolCallResult(strings.Join(lines, "\n"), nil), nil
+}
+
+func subscriptionExplain(params api.ToolHandlerParams) (*api.ToolCallResult, error) {
+	args := params.GetArguments()
+	namespace, err := common.GetOptionalString(args, "namespace")
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	name, err := common.GetRequiredString(args, "name")
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	gvk, err := common.ResolveGroupVersionKind(params.DiscoveryClient(), eventingGroup, subscriptionKind)
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	core := kubernetes.NewCore(params)
+	namespace = core.NamespaceOrDefault(namespace)
+	subscription, err := core.ResourcesGet(params.Context, &gvk, namespace, name)
+	if err != nil {
+		mcplog.HandleK8sError(params.Context, err, "subscription access")
+		return api.NewToolCallResult("", fmt.Errorf("failed to get Subscription: %w", err)), nil
+	}
+
+	var problems []string
+	sink, _, _ := unstructured.NestedString(subscription.Object, "spec", "sink")
+	source, _, _ := unstructured.NestedString(subscription.Object, "spec", "source")
+	types, _, _ := unstructured.NestedStringSlice(subscription.Object, "spec", "types")
+	lines := []string{
+		fmt.Sprintf("# Subscription %s/%s", namespace, name),
+		"- sink: " + common.ValueOrDash(sink),
+		"- source: " + common.ValueOrDash(source),
+		"- types: " + common.ValueOrDash(strings.Join(types, ", ")),
+		"- typeMatching: " + typeMatching(subscription),
+		"- ready: " + subscriptionReady(subscription),
+	}
+	for _, condition := range common.GetConditions(subscription) {
+		lines = append(lines, fmt.Sprintf("- condition %s=%s (%s): %s",
+			condition.Type, condition.Status, common.ValueOrDash(condition.Reason), common.ValueOrDash(condition.Message)))
+		if condition.Status == string(metav1.ConditionFalse) {
+			problems = append(problems, fmt.Sprintf("the Subscription condition %s is False: %s", condition.Type, common.ValueOrDash(condition.Message)))
+		}
+	}
+	if len(types) == 0 {
+		problems = append(problems, "the Subscription has no event types")
+	}
+	if source == "" && typeMatching(subscription) == defaultTypeMatching {
+		problems = append(problems, "the Subscription has no source, which is required with the standard typeMatching")
+	}
+	statusTypes, _, _ := unstructured.NestedSlice(subscription.Object, "status", "types")
+	for _, statusType := range statusTypes {
+		typeMap, _ := statusType.(map[string]any)
+		original, _ := typeMap["originalType"].(string)
+		clean, _ := typeMap["cleanType"].(string)
+		if original != "" && clean != "" && original != clean {
+			lines = append(lines, fmt.Sprintf("- type %s is cleaned to %s, publish the events with the original type", original, clean))
+		}
+	}
+	if len(types) > 0 && len(statusTypes) == 0 {
+		problems = append(problems, "the event types are not processed yet by the eventing manager")
+	}
+
+	lines = append(lines, "", "## Sink")
+	sinkLines, sinkProblems := sinkCheck(params, namespace, sink)
+	lines = append(lines, sinkLines...)
+	problems = append(problems, sinkProblems...)
+
+	lines = append(lines, "", "## Eventing backend")
+	if eventing, err := getEventingCR(params); err != nil {
+		lines = append(lines, fmt.Sprintf("- unavailable: %v", err))
+		problems = append(problems, err.Error())
+	} else {
+		backend, backendProblems := backendLines(eventing)
+		lines = append(lines, backend...)
+		problems = append(problems, backendProblems...)
+	}
+
+	lines = append(lines, "", fmt.Sprintf("## Publisher proxy %s/%s", kymaNamespace, publisherProxyName))
+	proxy, proxyProblems := publisherProxyLines(params)
+	lines = append(lines, proxy...)
+	problems = append(problems, proxyProblems...)
+
+	summary := []string{lines[0], "", "## Likely cause"}
+	if len(problems) == 0 {
+		summary = append(summary, "No problem found, publish a test event with eventing_event_publish and check the sink logs.")
+	} else {
+		summary = append(summary, problems[0])
+		for _, problem := range problems[1:] {
+			summary = append(summary, "- "+problem)
+		}
+	}
+	return api.NewToolCallResult(strings.Join(append(append(summary, ""), lines[1:]...), "\n"), nil), nil
+}
+
+// sinkCheck checks that an in-cluster sink Service exists, exposes the sink port and has ready endpoints.
+func sinkCheck(params api.ToolHandlerParams, namespace, sink string) ([]string, []string) {
+	if sink == "" {
+		return []string{"- not set"}, []string{"the Subscription has no sink"}
+	}
+	parsed, err := url.Parse(sink)
+	if err != nil || parsed.Hostname() == "" {
+		return []string{"- invalid"}, []string{fmt.Sprintf("the sink %s is not a valid URL", sink)}
+	}
+
+	// <service>, <service>.<namespace>, <service>.<namespace>.svc or <service>.<namespace>.svc.cluster.local
+	labels := strings.Split(parsed.Hostname(), ".")
+	serviceNamespace := namespace
+	if len(labels) >= 2 {
+		serviceNamespace = labels[1]
+	}
+	if domain := strings.Join(labels[min(len(labels), 2):], "."); domain != "" && domain != "svc" && domain != clusterServiceSuffix {
+		return []string{"- external sink " + parsed.Hostname() + ", not checked"}, nil
+	}
+	port := parsed.Port()
+	if port == "" {
+		port = "80"
+		if parsed.Scheme == "https" {
+			port = "443"
+		}
+	}
+
+	reference := serviceNamespace + "/" + labels[0]
+	service, err := params.CoreV1().Services(serviceNamespace).Get(params.Context, labels[0], metav1.GetOptions{})
+	if err != nil {
+		mcplog.HandleK8sError(params.Context, err, "service access")
+		return []string{fmt.Sprintf("- Service %s unavailable: %v", reference, err)}, []string{fmt.Sprintf("the sink Service %s cannot be read: %v", reference, err)}
+	}
+	var ports []string
+	portFound := false
+	for _, servicePort := range service.Spec.Ports {
+		ports = append(ports, strconv.Itoa(int(servicePort.Port)))
+		portFound = portFound || strconv.Itoa(int(servicePort.Port)) == port
+	}
+	lines := []string{fmt.Sprintf("- Service %s ports %s", reference, common.ValueOrDash(strings.Join(ports, ", ")))}
+	if !portFound {
+		return lines, []string{fmt.Sprintf("the sink Service %s has no port %s", reference, port)}
+	}
+	if len(service.Spec.Selector) > 0 {
+		ready := common.ServiceReadyEndpoints(params, serviceNamespace, labels[0])
+		if ready >= 0 {
+			lines = append(lines, fmt.Sprintf("- %d ready endpoints", ready))
+		}
+		if ready == 0 {
+			return lines, []string{fmt.Sprintf("the sink Service %s has no ready endpoints, check that its pods are running", reference)}
+		}
+	}
+	return lines, nil
+}
@@ -0,0 +1,32 @@
+package eventing
+
+import (
+	"slices"
+
+	"github.com/containers/kubernetes-mcp-server/pkg/api"
+	"github.com/containers/kubernetes-mcp-server/pkg/toolsets"
+)
+
+type Toolset struct{}
+
+var _ api.Toolset = (*Toolset)(nil)
+
+func (t *Toolset) GetName() string {
+	return "eventing"
+}
+
+func (t *Toolset) GetDescription() string {
+	return "Kyma Eventing tools for troubleshooting Subscriptions and publishing test events"
+}
+
+func (t *Toolset) GetTools(api.Openshift) []api.ServerTool {
+	return slices.Concat(initSubscriptions(), initPublish())
+}
+
+func (t *Toolset) GetPrompts() []api.ServerPrompt {
+	return nil
+}
+
+func init() {
+	toolsets.Register(&Toolset{})
+}
@@ -0,0 +1,276 @@
+package istio
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/containers/kubernetes-mcp-server/pkg/api"
+	"github.com/containers/kubernetes-mcp-server/pkg/mcplog"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/mfaizanse/ext-kyma-mcp/pkg/toolsets/common"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	operatorGroup = "operator.kyma-project.io"
+	istioKind     = "Istio"
+
+	istioNamespace     = "istio-system"
+	istiodName         = "istiod"
+	proxyContainerName = "istio-proxy"
+
+	injectionLabel         = "istio-injection"
+	revisionLabel          = "istio.io/rev"
+	podInjectionLabel      = "sidecar.istio.io/inject"
+	injectionEnabledValue  = "enabled"
+	injectionDisabledValue = "disabled"
+)
+
+func initStatus() []api.ServerTool {
+	return []api.ServerTool{
+		{
+			Tool: api.Tool{
+				Name: "istio_status",
+				Description: "Report the Kyma Istio module CR status and the control plane version, and per namespace the sidecar injection setting " +
+					"and the pods with injection enabled vs actually injected and the pods running outdated proxy versions",
+				InputSchema: &jsonschema.Schema{
+					Type: "object",
+					Properties: map[string]*jsonschema.Schema{
+						"namespace": {
+							Type:        "string",
+							Description: "Namespace to report the sidecar injection of (optional, reports all namespaces if not provided)",
+						},
+					},
+				},
+				Annotations: api.ToolAnnotations{
+					Title:           "Istio: Status",
+					ReadOnlyHint:    ptr.To(true),
+					DestructiveHint: ptr.To(false),
+					OpenWorldHint:   ptr.To(true),
+				},
+			},
+			Handler: istioStatus,
+		},
+	}
+}
+
+// resolveGVK resolves the served version of the kind of the API group.
+func istioStatus(params api.ToolHandlerParams) (*api.ToolCallResult, error) {
+	namespace, err := common.GetOptionalString(params.GetArguments(), "namespace")
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+
+	lines := []string{"# Istio module"}
+	lines = append(lines, istioCRLines(params)...)
+	controlPlaneVersion, err := controlPlaneVersion(params)
+	if err != nil {
+		lines = append(lines, fmt.Sprintf("- control plane version unavailable: %v", err))
+	} else {
+		lines = append(lines, "- control plane version: "+controlPlaneVersion)
+	}
+
+	pods, err := params.CoreV1().Pods(namespace).List(params.Context, metav1.ListOptions{})
+	if err != nil {
+		mcplog.HandleK8sError(params.Context, err, "pods listing")
+		return api.NewToolCallResult("", fmt.Errorf("failed to list pods: %w", err)), nil
+	}
+	namespaceLabels, err := listNamespaceLabels(params, namespace)
+	if err != nil {
+		lines = append(lines, fmt.Sprintf("- namespace injection labels unavailable: %v", err))
+	}
+
+	type namespaceSummary struct {
+		pods, enabled, injected, outdated int
+	}
+	summaries := make(map[string]*namespaceSummary)
+	var problems []string
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			continue
+		}
+		summary, ok := summaries[pod.Namespace]
+		if !ok {
+			summary = &namespaceSummary{}
+			summaries[pod.Namespace] = summary
+		}
+		summary.pods++
+		labels, known := namespaceLabels[pod.Namespace]
+		enabled := injectionEnabled(labels, pod.Labels)
+		proxyVersion, injected := sidecarVersion(pod)
+		if enabled {
+			summary.enabled++
+		}
+		if injected {
+			summary.injected++
+		}
+		switch {
+		case !known:
+			// without the namespace labels the injection setting is unknown
+		case enabled && !injected:
+			problems = append(problems, fmt.Sprintf("- %s/%s: injection enabled but no sidecar, restart its workload", pod.Namespace, pod.Name))
+		case !enabled && injected && pod.Namespace != istioNamespace:
+			problems = append(problems, fmt.Sprintf("- %s/%s: sidecar injected but injection is not enabled anymore, restart its workload", pod.Namespace, pod.Name))
+		}
+		if injected && controlPlaneVersion != "" && proxyVersion != "" && proxyVersion != controlPlaneVersion {
+			summary.outdated++
+			problems = append(problems, fmt.Sprintf("- %s/%s: proxy %s differs from the control plane %s, restart its workload", pod.Namespace, pod.Name, proxyVersion, controlPlaneVersion))
+		}
+	}
+
+	names := make([]string, 0, len(summaries))
+	for name := range summaries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	buf := new(bytes.Buffer)
+	w := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "NAMESPACE\tINJECTION\tPODS\tINJECTION ENABLED\tINJECTED\tOUTDATED")
+	for _, name := range names {
+		summary := summaries[name]
+		injection, enabled := "-", "-"
+		if labels, ok := namespaceLabels[name]; ok {
+			injection = namespaceInjection(labels)
+			enabled = strconv.Itoa(summary.enabled)
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%d\t%d\n",
+			name, injection, summary.pods, enabled, summary.injected, summary.outdated)
+	}
+	_ = w.Flush()
+
+	lines = append(lines, "", "# Sidecar injection", strings.TrimSpace(buf.String()))
+	if len(problems) > 0 {
+		lines = append(lines, "", "# Pods to check")
+		lines = append(lines, problems...)
+	}
+	return api.NewToolCallResult(strings.Join(lines, "\n"), nil), nil
+}
+
+// listNamespaceLabels returns the labels of the namespace, or of all the namespaces if empty.
+func listNamespaceLabels(params api.ToolHandlerParams, namespace string) (map[string]map[string]string, error) {
+	if namespace != "" {
+		ns, err := params.CoreV1().Namespaces().Get(params.Context, namespace, metav1.GetOptions{})
+		if err != nil {
+			mcplog.HandleK8sError(params.Context, err, "namespace access")
+			return nil, err
+		}
+		return map[string]map[string]string{ns.Name: ns.Labels}, nil
+	}
+	namespaces, err := params.CoreV1().Namespaces().List(params.Context, metav1.ListOptions{})
+	if err != nil {
+		mcplog.HandleK8sError(params.Context, err, "namespaces listing")
+		return nil, err
+	}
+	ret := make(map[string]map[string]string, len(namespaces.Items))
+	for _, ns := range namespaces.Items {
+		ret[ns.Name] = ns.Labels
+	}
+	return ret, nil
+}
+
+// istioCRLines describes the state and the conditions of the Istio CRs.
+func istioCRLines(params api.ToolHandlerParams) []string {
+	gvk, err := common.ResolveGroupVersionKind(params.DiscoveryClient(), operatorGroup, istioKind)
+	if err != nil {
+		return []string{fmt.Sprintf("- Istio CR unavailable: %v", err)}
+	}
+	istios, err := common.ListResources(params, gvk, "")
+	if err != nil {
+		return []string{fmt.Sprintf("- Istio CR unavailable: %v", err)}
+	}
+	if len(istios) == 0 {
+		return []string{"- no Istio CR found, is the istio module enabled?"}
+	}
+	var ret []string
+	for i := range istios {
+		istio := &istios[i]
+		state, _, _ := unstructured.NestedString(istio.Object, "status", "state")
+		description, _, _ := unstructured.NestedString(istio.Object, "status", "description")
+		ret = append(ret, fmt.Sprintf("- Istio CR %s/%s: %s %s", istio.GetNamespace(), istio.GetName(), common.ValueOrDash(state), description))
+		for _, condition := range common.GetConditions(istio) {
+			if condition.Status != string(metav1.ConditionTrue) {
+				ret = append(ret, fmt.Sprintf("  - condition %s=%s (%s): %s",
+					condition.Type, condition.Status, common.ValueOrDash(condition.Reason), common.ValueOrDash(condition.Message)))
+			}
+		}
+	}
+	return ret
+}
+
+// controlPlaneVersion returns the version of istiod, from its image tag.
+func controlPlaneVersion(params api.ToolHandlerParams) (string, error) {
+	deployment, err := params.AppsV1().Deployments(istioNamespace).Get(params.Context, istiodName, metav1.GetOptions{})
+	if err != nil {
+		mcplog.HandleK8sError(params.Context, err, "deployment access")
+		return "", err
+	}
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		if container.Name == "discovery" || len(deployment.Spec.Template.Spec.Containers) == 1 {
+			return imageVersion(container.Image), nil
+		}
+	}
+	return "", fmt.Errorf("no discovery container in %s/%s", istioNamespace, istiodName)
+}
+
+// imageVersion returns the version of an image tag, without its flavor suffix (e.g. 1.22.3 of pilot:1.22.3-distroless).
+func imageVersion(image string) string {
+	if at := strings.Index(image, "@"); at >= 0 {
+		image = image[:at]
+	}
+	colon := strings.LastIndex(image, ":")
+	if colon < 0 || strings.Contains(image[colon:], "/") {
+		return ""
+	}
+	version, _, _ := strings.Cut(image[colon+1:], "-")
+	return version
+}
+
+// sidecarVersion returns the proxy version of the pod and whether it has an istio-proxy sidecar, also as a native sidecar init container.
+func sidecarVersion(pod *v1.Pod) (string, bool) {
+	containers := make([]v1.Container, 0, len(pod.Spec.InitContainers)+len(pod.Spec.Containers))
+	containers = append(containers, pod.Spec.InitContainers...)
+	containers = append(containers, pod.Spec.Containers...)
+	for _, container := range containers {
+		if container.Name == proxyContainerName {
+			return imageVersion(container.Image), true
+		}
+	}
+	return "", false
+}
+
+// namespaceInjection returns the sidecar injection setting of the namespace.
+func namespaceInjection(labels map[string]string) string {
+	if value, ok := labels[injectionLabel]; ok {
+		return value
+	}
+	if revision, ok := labels[revisionLabel]; ok {
+		return "rev " + revision
+	}
+	return "-"
+}
+
+// injectionEnabled returns whether the sidecar is injected in a pod of the namespace, the pod label overrides the namespace label.
+func injectionEnabled(namespaceLabels, podLabels map[string]string) bool {
+	if value, ok := podLabels[podInjectionLabel]; ok {
+		if value == "false" {
+			return false
+		}
+		if value == "true" {
+			// the pod label only enables the injection in namespaces that do not disable it
+			return namespaceLabels[injectionLabel] != injectionDisabledValue
+		}
+	}
+	if namespaceLabels[injectionLabel] == injectionEnabledValue {
+		return true
+	}
+	_, hasRevision := namespaceLabels[revisionLabel]
+	return hasRevision && namespaceLabels[injectionLabel] != injectionDisabledValue
+}
@@ -0,0 +1,32 @@
+package istio
+
+import (
+	"slices"
+
+	"github.com/containers/kubernetes-mcp-server/pkg/api"
+	"github.com/containers/kubernetes-mcp-server/pkg/toolsets"
+)
+
+type Toolset struct{}
+
+var _ api.Toolset = (*Toolset)(nil)
+
+func (t *Toolset) GetName() string {
+	return "istio"
+}
+
+func (t *Toolset) GetDescription() string {
+	return "Kyma Istio tools for diagnosing the Istio module, sidecar injection and the policies affecting workloads"
+}
+
+func (t *Toolset) GetTools(api.Openshift) []api.ServerTool {
+	return slices.Concat(initStatus(), initWorkload())
+}
+
+func (t *Toolset) GetPrompts() []api.ServerPrompt {
+	return nil
+}
+
+func init() {
+	toolsets.Register(&Toolset{})
+}
@@ -0,0 +1,354 @@
+package istio
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/containers/kubernetes-mcp-server/pkg/api"
+	"github.com/containers/kubernetes-mcp-server/pkg/mcplog"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/mfaizanse/ext-kyma-mcp/pkg/toolsets/common"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	securityGroup   = "security.istio.io"
+	networkingGroup = "networking.istio.io"
+
+	peerAuthenticationKind  = "PeerAuthentication"
+	authorizationPolicyKind = "AuthorizationPolicy"
+	virtualServiceKind      = "VirtualService"
+
+	workloadKindDeployment  = "Deployment"
+	workloadKindStatefulSet = "StatefulSet"
+	workloadKindDaemonSet   = "DaemonSet"
+)
+
+func initWorkload() []api.ServerTool {
+	return []api.ServerTool{
+		{
+			Tool: api.Tool{
+				Name: "istio_workload_summary",
+				Description: "Summarize the Istio setup of a workload: the sidecar injection and proxy versions of its pods, " +
+					"and the PeerAuthentications, AuthorizationPolicies and VirtualServices affecting it (mesh, namespace or workload wide)",
+				InputSchema: &jsonschema.Schema{
+					Type: "object",
+					Properties: map[string]*jsonschema.Schema{
+						"namespace": {
+							Type:        "string",
+							Description: "Namespace of the workload (optional, defaults to the configured namespace)",
+						},
+						"name": {
+							Type:        "string",
+							Description: "Name of the workload",
+						},
+						"kind": {
+							Type:        "string",
+							Description: "Kind of the workload (defaults to Deployment)",
+							Enum:        []any{workloadKindDeployment, workloadKindStatefulSet, workloadKindDaemonSet},
+						},
+					},
+					Required: []string{"name"},
+				},
+				Annotations: api.ToolAnnotations{
+					Title:           "Istio: Workload Summary",
+					ReadOnlyHint:    ptr.To(true),
+					DestructiveHint: ptr.To(false),
+					OpenWorldHint:   ptr.To(true),
+				},
+			},
+			Handler: workloadSummary,
+		},
+	}
+}
+
+func workloadSummary(params api.ToolHandlerParams) (*api.ToolCallResult, error) {
+	args := params.GetArguments()
+	namespace, err := common.GetOptionalString(args, "namespace")
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	namespace = params.NamespaceOrDefault(namespace)
+	name, err := common.GetRequiredString(args, "name")
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	kind, err := common.GetOptionalStringDefault(args, "kind", workloadKindDeployment)
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+
+	podLabels, selector, err := workloadSelector(params, kind, namespace, name)
+	if err != nil {
+		mcplog.HandleK8sError(params.Context, err, "workload access")
+		return api.NewToolCallResult("", fmt.Errorf("failed to get %s %s/%s: %w", kind, namespace, name, err)), nil
+	}
+
+	lines := []string{fmt.Sprintf("# %s %s/%s", kind, namespace, name)}
+	lines = append(lines, sidecarLines(params, namespace, podLabels, selector)...)
+
+	services := workloadServices(params, namespace, podLabels)
+	lines = append(lines, "- services: "+common.ValueOrDash(strings.Join(services, ", ")))
+
+	lines = append(lines, "", "## PeerAuthentications")
+	lines = append(lines, policyLines(params, securityGroup, peerAuthenticationKind, namespace, podLabels, peerAuthenticationSummary)...)
+	lines = append(lines, "", "## AuthorizationPolicies")
+	lines = append(lines, policyLines(params, securityGroup, authorizationPolicyKind, namespace, podLabels, authorizationPolicySummary)...)
+	lines = append(lines, "", "## VirtualServices")
+	lines = append(lines, virtualServiceLines(params, namespace, services)...)
+	return api.NewToolCallResult(strings.Join(lines, "\n"), nil), nil
+}
+
+// workloadSelector returns the pod template labels and the selector of the workload.
+func workloadSelector(params api.ToolHandlerParams, kind, namespace, name string) (map[string]string, *metav1.LabelSelector, error) {
+	switch kind {
+	case workloadKindDeployment:
+		workload, err := params.AppsV1().Deployments(namespace).Get(params.Context, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, err
+		}
+		return workload.Spec.Template.Labels, workload.Spec.Selector, nil
+	case workloadKindStatefulSet:
+		workload, err := params.AppsV1().StatefulSets(namespace).Get(params.Context, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, err
+		}
+		return workload.Spec.Template.Labels, workload.Spec.Selector, nil
+	case workloadKindDaemonSet:
+		workload, err := params.AppsV1().DaemonSets(namespace).Get(params.Context, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, err
+		}
+		return workload.Spec.Template.Labels, workload.Spec.Selector, nil
+	}
+	return nil, nil, fmt.Errorf("kind must be %s, %s or %s", workloadKindDeployment, workloadKindStatefulSet, workloadKindDaemonSet)
+}
+
+// sidecarLines summarizes the sidecar injection and the proxy versions of the workload pods.
+func sidecarLines(params api.ToolHandlerParams, namespace string, podLabels map[string]string, selector *metav1.LabelSelector) []string {
+	ns, err := params.CoreV1().Namespaces().Get(params.Context, namespace, metav1.GetOptions{})
+	if err != nil {
+		mcplog.HandleK8sError(params.Context, err, "namespace access")
+		return []string{fmt.Sprintf("- sidecar injection unavailable: %v", err)}
+	}
+	enabled := injectionEnabled(ns.Labels, podLabels)
+	lines := []string{fmt.Sprintf("- sidecar injection: %t (namespace %s, pod label %s)",
+		enabled, namespaceInjection(ns.Labels), common.ValueOrDash(podLabels[podInjectionLabel]))}
+
+	podSelector, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return append(lines, fmt.Sprintf("- pods unavailable: %v", err))
+	}
+	pods, err := params.CoreV1().Pods(namespace).List(params.Context, metav1.ListOptions{LabelSelector: podSelector.String()})
+	if err != nil {
+		mcplog.HandleK8sError(params.Context, err, "pods listing")
+		return append(lines, fmt.Sprintf("- pods unavailable: %v", err))
+	}
+	controlPlane, _ := controlPlaneVersion(params)
+	running, injected := 0, 0
+	versions := make(map[string]int)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			continue
+		}
+		running++
+		if version, ok := sidecarVersion(pod); ok {
+			injected++
+			versions[common.ValueOrDash(version)]++
+		}
+	}
+	lines = append(lines, fmt.Sprintf("- pods injected: %d of %d", injected, running))
+	if enabled && injected < running {
+		lines = append(lines, "- WARNING: some pods have no sidecar although injection is enabled, restart the workload")
+	}
+	if !enabled && injected > 0 {
+		lines = append(lines, "- WARNING: some pods have a sidecar although injection is not enabled anymore, restart the workload")
+	}
+	sortedVersions := make([]string, 0, len(versions))
+	for version := range versions {
+		sortedVersions = append(sortedVersions, version)
+	}
+	sort.Strings(sortedVersions)
+	for _, version := range sortedVersions {
+		line := fmt.Sprintf("- proxy %s: %d pods", version, versions[version])
+		if controlPlane != "" && version != controlPlane {
+			line += fmt.Sprintf(" (outdated, control plane %s, restart the workload)", controlPlane)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// workloadServices returns the names of the Services of the namespace selecting the workload pods.
+func workloadServices(params api.ToolHandlerParams, namespace string, podLabels map[string]string) []string {
+	services, err := params.CoreV1().Services(namespace).List(params.Context, metav1.ListOptions{})
+	if err != nil {
+		mcplog.HandleK8sError(params.Context, err, "services listing")
+		return nil
+	}
+	var ret []string
+	for _, service := range services.Items {
+		if len(service.Spec.Selector) > 0 && labels.SelectorFromSet(service.Spec.Selector).Matches(labels.Set(podLabels)) {
+			ret = append(ret, service.Name)
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// policyLines lists the policies of the kind applying to the workload: mesh wide (istio-system without selector),
+// namespace wide (without selector) and workload specific (selector matching the pod labels, also from istio-system).
+func policyLines(params api.ToolHandlerParams, group, kind, namespace string, podLabels map[string]string,
+	summary func(policy *unstructured.Unstructured) string) []string {
+	gvk, err := common.ResolveGroupVersionKind(params.DiscoveryClient(), group, kind)
+	if err != nil {
+		return []string{fmt.Sprintf("- unavailable: %v", err)}
+	}
+	var ret []string
+	for _, policyNamespace := range []string{istioNamespace, namespace} {
+		policies, err := common.ListResources(params, gvk, policyNamespace)
+		if err != nil {
+			ret = append(ret, fmt.Sprintf("- %s unavailable: %v", policyNamespace, err))
+			continue
+		}
+		for i := range policies {
+			policy := &policies[i]
+			matchLabels, hasSelector, _ := unstructured.NestedStringMap(policy.Object, "spec", "selector", "matchLabels")
+			var scope string
+			switch {
+			case len(matchLabels) > 0:
+				// a selector in the root namespace selects the workloads of all namespaces
+				if !labels.SelectorFromSet(matchLabels).Matches(labels.Set(podLabels)) {
+					continue
+				}
+				scope = "workload"
+			case !hasSelector || len(matchLabels) == 0:
+				scope = "namespace"
+				if policyNamespace == istioNamespace && namespace != istioNamespace {
+					scope = "mesh"
+				}
+			default:
+				continue
+			}
+			ret = append(ret, fmt.Sprintf("- %s/%s (%s wide): %s", policy.GetNamespace(), policy.GetName(), scope, summary(policy)))
+		}
+		if namespace == istioNamespace {
+			break
+		}
+	}
+	if len(ret) == 0 {
+		ret = append(ret, "- none")
+	}
+	return ret
+}
+
+func peerAuthenticationSummary(policy *unstructured.Unstructured) string {
+	mode, _, _ := unstructured.NestedString(policy.Object, "spec", "mtls", "mode")
+	if mode == "" {
+		mode = "UNSET (inherited)"
+	}
+	ret := "mTLS " + mode
+	portLevel, _, _ := unstructured.NestedMap(policy.Object, "spec", "portLevelMtls")
+	if len(portLevel) > 0 {
+		ports := make([]string, 0, len(portLevel))
+		for port, setting := range portLevel {
+			portMode, _, _ := unstructured.NestedString(map[string]any{"setting": setting}, "setting", "mode")
+			ports = append(ports, port+"="+common.ValueOrDash(portMode))
+		}
+		sort.Strings(ports)
+		ret += ", ports " + strings.Join(ports, " ")
+	}
+	return ret
+}
+
+func authorizationPolicySummary(policy *unstructured.Unstructured) string {
+	action, _, _ := unstructured.NestedString(policy.Object, "spec", "action")
+	if action == "" {
+		action = "ALLOW"
+	}
+	rules, hasRules, _ := unstructured.NestedSlice(policy.Object, "spec", "rules")
+	ret := fmt.Sprintf("%s, %d rules", action, len(rules))
+	if provider, _, _ := unstructured.NestedString(policy.Object, "spec", "provider", "name"); provider != "" {
+		ret += ", provider " + provider
+	}
+	switch {
+	case action == "ALLOW" && !hasRules:
+		ret += " (denies all requests)"
+	case action == "DENY":
+		ret += " (matching requests are denied)"
+	}
+	return ret
+}
+
+// virtualServiceLines lists the VirtualServices of all namespaces routing to the Services of the workload.
+func virtualServiceLines(params api.ToolHandlerParams, namespace string, services []string) []string {
+	if len(services) == 0 {
+		return []string{"- none, the workload has no Service"}
+	}
+	gvk, err := common.ResolveGroupVersionKind(params.DiscoveryClient(), networkingGroup, virtualServiceKind)
+	if err != nil {
+		return []string{fmt.Sprintf("- unavailable: %v", err)}
+	}
+	virtualServices, err := common.ListResources(params, gvk, "")
+	if err != nil {
+		return []string{fmt.Sprintf("- unavailable: %v", err)}
+	}
+
+	var ret []string
+	for i := range virtualServices {
+		virtualService := &virtualServices[i]
+		destinations := make(map[string]bool)
+		for _, route := range []string{"http", "tcp", "tls"} {
+			routes, _, _ := unstructured.NestedSlice(virtualService.Object, "spec", route)
+			for _, r := range routes {
+				destinationRoutes, _, _ := unstructured.NestedSlice(map[string]any{"r": r}, "r", "route")
+				for _, destinationRoute := range destinationRoutes {
+					host, _, _ := unstructured.NestedString(map[string]any{"d": destinationRoute}, "d", "destination", "host")
+					if service, ok := serviceOfHost(host, virtualService.GetNamespace(), namespace, services); ok {
+						destinations[service] = true
+					}
+				}
+			}
+		}
+		if len(destinations) == 0 {
+			continue
+		}
+		hosts, _, _ := unstructured.NestedStringSlice(virtualService.Object, "spec", "hosts")
+		gateways, _, _ := unstructured.NestedStringSlice(virtualService.Object, "spec", "gateways")
+		routed := make([]string, 0, len(destinations))
+		for service := range destinations {
+			routed = append(routed, service)
+		}
+		sort.Strings(routed)
+		ret = append(ret, fmt.Sprintf("- %s/%s: hosts %s, gateways %s, routes to %s", virtualService.GetNamespace(), virtualService.GetName(),
+			common.ValueOrDash(strings.Join(hosts, ",")), common.ValueOrDash(strings.Join(gateways, ",")), strings.Join(routed, ",")))
+	}
+	if len(ret) == 0 {
+		ret = append(ret, "- none")
+	}
+	return ret
+}
+
+// serviceOfHost returns the Service of the workload a destination host (short, namespaced or fully qualified) refers to.
+func serviceOfHost(host, virtualServiceNamespace, namespace string, services []string) (string, bool) {
+	labels := strings.Split(host, ".")
+	hostNamespace := virtualServiceNamespace
+	if len(labels) >= 2 {
+		hostNamespace = labels[1]
+	}
+	if hostNamespace != namespace {
+		return "", false
+	}
+	for _, service := range services {
+		if labels[0] == service {
+			return service, true
+		}
+	}
+	return "", false
+}
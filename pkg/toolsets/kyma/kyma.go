@@ -1,25 +1,27 @@
 package kyma
 
 import (
+	"bytes"
 	"fmt"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/containers/kubernetes-mcp-server/pkg/api"
 	"github.com/containers/kubernetes-mcp-server/pkg/kubernetes"
 	"github.com/containers/kubernetes-mcp-server/pkg/mcplog"
 	"github.com/containers/kubernetes-mcp-server/pkg/output"
 	"github.com/google/jsonschema-go/jsonschema"
+	extkubernetes "github.com/mfaizanse/ext-kyma-mcp/pkg/kubernetes"
 	"github.com/mfaizanse/ext-kyma-mcp/pkg/saphelp"
 	"github.com/mfaizanse/ext-kyma-mcp/pkg/toolsets/common"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/utils/ptr"
 )
 
 const (
-	defaultKymaNamespace  = "kyma-system"
-	defaultKymaName       = "default"
-	defaultKymaAPIVersion = "operator.kyma-project.io/v1beta2"
-	kymaKind              = "Kyma"
+	defaultKymaNamespace = "kyma-system"
+	kymaKind             = "Kyma"
 )
 
 func initKyma() []api.ServerTool {
@@ -29,21 +31,8 @@ func initKyma() []api.ServerTool {
 				Name:        "kyma_get",
 				Description: "Get the Kyma custom resource from the cluster",
 				InputSchema: &jsonschema.Schema{
-					Type: "object",
-					Properties: map[string]*jsonschema.Schema{
-						"namespace": {
-							Type:        "string",
-							Description: "Namespace of the Kyma CR (defaults to kyma-system)",
-						},
-						"name": {
-							Type:        "string",
-							Description: "Name of the Kyma CR (defaults to default)",
-						},
-						"apiVersion": {
-							Type:        "string",
-							Description: "Kyma API version (defaults to operator.kyma-project.io/v1beta2)",
-						},
-					},
+					Type:       "object",
+					Properties: kymaCRProperties(),
 				},
 				Annotations: api.ToolAnnotations{
 					Title:           "Kyma: Get",
@@ -56,8 +45,9 @@ func initKyma() []api.ServerTool {
 		},
 		{
 			Tool: api.Tool{
-				Name:        "kyma_find_resource_version",
-				Description: "Find the apiVersion for a Kyma resource kind (returns the first matched result)",
+				Name: "kyma_find_resource_version",
+				Description: "Find the apiVersions serving a Kyma resource kind: every matching group/version with the resource plural, namespaced or cluster scope, " +
+					"short names, supported verbs and the preferred version of each group. Kinds may exist in several groups (e.g. Gateway in Istio and Gateway API), filter them with group.",
 				InputSchema: &jsonschema.Schema{
 					Type: "object",
 					Properties: map[string]*jsonschema.Schema{
@@ -65,6 +55,10 @@ func initKyma() []api.ServerTool {
 							Type:        "string",
 							Description: "Kyma resource kind (e.g., Function, APIRule, TracePipeline) in PascalCase (singular)",
 						},
+						"group": {
+							Type:        "string",
+							Description: "API group to find the kind in (optional, e.g. networking.istio.io, core for the core group)",
+						},
 					},
 					Required: []string{"resourceKind"},
 				},
@@ -116,41 +110,78 @@ func initKyma() []api.ServerTool {
 }
 
 func kymaGet(params api.ToolHandlerParams) (*api.ToolCallResult, error) {
-	args := params.GetArguments()
-
-	name, err := common.GetOptionalStringDefault(args, "name", defaultKymaName)
+	ret, err := getKymaCR(params)
 	if err != nil {
 		return api.NewToolCallResult("", err), nil
 	}
 
-	namespace, err := common.GetOptionalStringDefault(args, "namespace", defaultKymaNamespace)
+	marshalled, err := output.MarshalYaml(ret)
 	if err != nil {
-		return api.NewToolCallResult("", err), nil
+		return api.NewToolCallResult("", fmt.Errorf("failed to marshal Kyma CR: %w", err)), nil
 	}
 
-	apiVersion, err := common.GetOptionalStringDefault(args, "apiVersion", defaultKymaAPIVersion)
+	return api.NewToolCallResult(strings.TrimSpace(marshalled), nil), nil
+}
+
+// getKymaCR gets the Kyma CR selected by the optional namespace, name and apiVersion arguments, the missing ones are
+// resolved from the Kyma CR of the cluster.
+func getKymaCR(params api.ToolHandlerParams) (*unstructured.Unstructured, error) {
+	args := params.GetArguments()
+
+	name, err := common.GetOptionalString(args, "name")
 	if err != nil {
-		return api.NewToolCallResult("", err), nil
+		return nil, err
 	}
 
-	gv, err := schema.ParseGroupVersion(apiVersion)
+	namespace, err := common.GetOptionalString(args, "namespace")
 	if err != nil {
-		return api.NewToolCallResult("", fmt.Errorf("invalid apiVersion: %w", err)), nil
+		return nil, err
 	}
-	gvk := &schema.GroupVersionKind{Group: gv.Group, Version: gv.Version, Kind: kymaKind}
 
-	ret, err := kubernetes.NewCore(params).ResourcesGet(params.Context, gvk, namespace, name)
+	apiVersion, err := common.GetOptionalString(args, "apiVersion")
 	if err != nil {
-		mcplog.HandleK8sError(params.Context, err, "kyma resource access")
-		return api.NewToolCallResult("", fmt.Errorf("failed to get Kyma CR: %w", err)), nil
+		return nil, err
 	}
 
-	marshalled, err := output.MarshalYaml(ret)
-	if err != nil {
-		return api.NewToolCallResult("", fmt.Errorf("failed to marshal Kyma CR: %w", err)), nil
+	var gvk schema.GroupVersionKind
+	switch {
+	case apiVersion != "":
+		gv, err := schema.ParseGroupVersion(apiVersion)
+		if err != nil {
+			return nil, fmt.Errorf("invalid apiVersion: %w", err)
+		}
+		gvk = gv.WithKind(kymaKind)
+	case name != "" && namespace != "":
+		// the Kyma CR is known, only its version is resolved
+		gvk, err = extkubernetes.GetKymaGroupVersionKind(params.Context, params.KubernetesClient)
+		if err != nil {
+			mcplog.HandleK8sError(params.Context, err, "kyma resource access")
+			return nil, fmt.Errorf("failed to resolve the Kyma API version: %w", err)
+		}
+	}
+	if name == "" || namespace == "" {
+		kymaCR, err := extkubernetes.GetKymaCR(params.Context, params.KubernetesClient)
+		if err != nil {
+			mcplog.HandleK8sError(params.Context, err, "kyma resource access")
+			return nil, fmt.Errorf("failed to resolve the Kyma CR: %w", err)
+		}
+		if name == "" {
+			name = kymaCR.Name
+		}
+		if namespace == "" {
+			namespace = kymaCR.Namespace
+		}
+		if apiVersion == "" {
+			gvk = kymaCR.GroupVersionKind
+		}
 	}
 
-	return api.NewToolCallResult(strings.TrimSpace(marshalled), nil), nil
+	ret, err := kubernetes.NewCore(params).ResourcesGet(params.Context, &gvk, namespace, name)
+	if err != nil {
+		mcplog.HandleK8sError(params.Context, err, "kyma resource access")
+		return nil, fmt.Errorf("failed to get Kyma CR: %w", err)
+	}
+	return ret, nil
 }
 
 func kymaResourceVersion(params api.ToolHandlerParams) (*api.ToolCallResult, error) {
@@ -159,13 +190,44 @@ func kymaResourceVersion(params api.ToolHandlerParams) (*api.ToolCallResult, err
 	if err != nil {
 		return api.NewToolCallResult("", err), nil
 	}
+	group, err := common.GetOptionalString(args, "group")
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
 
-	version, err := common.ResolveResourceVersion(params.DiscoveryClient(), kind)
+	matches, failed, err := common.FindResources(params.DiscoveryClient(), group, kind)
 	if err != nil {
+		mcplog.HandleK8sError(params.Context, err, "resources discovery")
 		return api.NewToolCallResult("", err), nil
 	}
 
-	return api.NewToolCallResult(version, nil), nil
+	var lines []string
+	if len(matches) == 0 {
+		lines = append(lines, "# No resource found for kind "+kind)
+	} else {
+		buf := new(bytes.Buffer)
+		w := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "APIVERSION\tKIND\tRESOURCE\tSCOPE\tSHORTNAMES\tVERBS\tPREFERRED")
+		groups := make(map[string]bool)
+		for _, match := range matches {
+			groups[match.GroupVersion.Group] = true
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%t\n", match.GroupVersion, match.Kind, match.Resource, match.Scope(),
+				common.ValueOrDash(strings.Join(match.ShortNames, ",")), common.ValueOrDash(strings.Join(match.Verbs, ",")), match.Preferred)
+		}
+		_ = w.Flush()
+		lines = append(lines, fmt.Sprintf("# %d resources found for kind %s", len(matches), kind), strings.TrimSpace(buf.String()))
+		if len(groups) > 1 {
+			lines = append(lines, fmt.Sprintf("The kind is served by %d groups, select the group of the resource you are looking for.", len(groups)))
+		}
+	}
+	if len(failed) > 0 {
+		// the kind may be served by a group version whose discovery failed
+		lines = append(lines, "# Discovery failed for the following group versions, the results may be incomplete")
+		for _, failure := range common.DiscoveryFailures(failed) {
+			lines = append(lines, "- "+failure)
+		}
+	}
+	return api.NewToolCallResult(strings.Join(lines, "\n"), nil), nil
 }
 
 func kymaHelpSemanticSearch(params api.ToolHandlerParams) (*api.ToolCallResult, error) {
@@ -0,0 +1,343 @@
+package kyma
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/containers/kubernetes-mcp-server/pkg/api"
+	"github.com/containers/kubernetes-mcp-server/pkg/mcplog"
+	"github.com/containers/kubernetes-mcp-server/pkg/output"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/mfaizanse/ext-kyma-mcp/pkg/toolsets/common"
+	"github.com/pmezard/go-difflib/difflib"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+)
+
+const customResourcePolicyIgnore = "Ignore"
+
+// kymaCRProperties are the input properties selecting the Kyma CR, shared by the module tools.
+func kymaCRProperties() map[string]*jsonschema.Schema {
+	return map[string]*jsonschema.Schema{
+		"namespace": {
+			Type:        "string",
+			Description: "Namespace of the Kyma CR (optional, defaults to kyma-system, or the namespace of the Kyma CR found in the cluster)",
+		},
+		"name": {
+			Type:        "string",
+			Description: "Name of the Kyma CR (optional, defaults to default, or the name of the Kyma CR found in the cluster)",
+		},
+		"apiVersion": {
+			Type:        "string",
+			Description: "Kyma API version (optional, defaults to the storage version served by the cluster)",
+		},
+	}
+}
+
+func initModuleActions() []api.ServerTool {
+	enableProperties := kymaCRProperties()
+	enableProperties["module"] = &jsonschema.Schema{
+		Type:        "string",
+		Description: "Name of the module to enable (e.g. serverless, api-gateway, telemetry)",
+	}
+	enableProperties["channel"] = &jsonschema.Schema{
+		Type:        "string",
+		Description: "Channel of the module (defaults to the channel of the Kyma CR)",
+	}
+	enableProperties["customResourcePolicy"] = &jsonschema.Schema{
+		Type:        "string",
+		Enum:        []any{defaultCustomResourcePolicy, customResourcePolicyIgnore},
+		Description: "Whether the default module CR is created and deleted with the module (CreateAndDelete, default) or left to the user (Ignore)",
+	}
+	enableProperties["dryRun"] = dryRunProperty()
+
+	disableProperties := kymaCRProperties()
+	disableProperties["module"] = &jsonschema.Schema{
+		Type:        "string",
+		Description: "Name of the module to disable",
+	}
+	disableProperties["dryRun"] = dryRunProperty()
+
+	setChannelProperties := kymaCRProperties()
+	setChannelProperties["module"] = &jsonschema.Schema{
+		Type:        "string",
+		Description: "Name of the module whose channel is switched",
+	}
+	setChannelProperties["channel"] = &jsonschema.Schema{
+		Type:        "string",
+		Description: "New channel of the module (e.g. regular, fast)",
+	}
+	setChannelProperties["dryRun"] = dryRunProperty()
+
+	return []api.ServerTool{
+		{
+			Tool: api.Tool{
+				Name:        "kyma_module_enable",
+				Description: "Enable a Kyma module by adding it to spec.modules of the Kyma CR. The module and channel are validated against the available ModuleTemplates. Use dryRun to preview the change as a diff.",
+				InputSchema: &jsonschema.Schema{
+					Type:       "object",
+					Properties: enableProperties,
+					Required:   []string{"module"},
+				},
+				Annotations: api.ToolAnnotations{
+					Title:           "Kyma: Module Enable",
+					ReadOnlyHint:    ptr.To(false),
+					DestructiveHint: ptr.To(false),
+					IdempotentHint:  ptr.To(false),
+					OpenWorldHint:   ptr.To(true),
+				},
+			},
+			Handler: kymaModuleEnable,
+		},
+		{
+			Tool: api.Tool{
+				Name: "kyma_module_disable",
+				Description: "Disable a Kyma module by removing it from spec.modules of the Kyma CR. " +
+					"With the CreateAndDelete customResourcePolicy the module CR and the resources it manages are deleted. Use dryRun to preview the change as a diff.",
+				InputSchema: &jsonschema.Schema{
+					Type:       "object",
+					Properties: disableProperties,
+					Required:   []string{"module"},
+				},
+				Annotations: api.ToolAnnotations{
+					Title:           "Kyma: Module Disable",
+					ReadOnlyHint:    ptr.To(false),
+					DestructiveHint: ptr.To(true),
+					IdempotentHint:  ptr.To(false),
+					OpenWorldHint:   ptr.To(true),
+				},
+			},
+			Handler: kymaModuleDisable,
+		},
+		{
+			Tool: api.Tool{
+				Name:        "kyma_module_set_channel",
+				Description: "Switch the channel of an enabled Kyma module in spec.modules of the Kyma CR, which upgrades the module to the channel version. The channel is validated against the available ModuleTemplates. Use dryRun to preview the change as a diff.",
+				InputSchema: &jsonschema.Schema{
+					Type:       "object",
+					Properties: setChannelProperties,
+					Required:   []string{"module", "channel"},
+				},
+				Annotations: api.ToolAnnotations{
+					Title:           "Kyma: Module Set Channel",
+					ReadOnlyHint:    ptr.To(false),
+					DestructiveHint: ptr.To(true),
+					IdempotentHint:  ptr.To(true),
+					OpenWorldHint:   ptr.To(true),
+				},
+			},
+			Handler: kymaModuleSetChannel,
+		},
+	}
+}
+
+func dryRunProperty() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type:        "boolean",
+		Description: "If true, the change is validated by the API server (server-side dry run) and returned as a diff without being applied (defaults to false)",
+	}
+}
+
+func kymaModuleEnable(params api.ToolHandlerParams) (*api.ToolCallResult, error) {
+	args := params.GetArguments()
+	moduleName, err := common.GetRequiredString(args, "module")
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	channel, err := common.GetOptionalString(args, "channel")
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	policy, err := common.GetOptionalString(args, "customResourcePolicy")
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	if policy != "" && policy != defaultCustomResourcePolicy && policy != customResourcePolicyIgnore {
+		return api.NewToolCallResult("", fmt.Errorf("customResourcePolicy must be %s or %s", defaultCustomResourcePolicy, customResourcePolicyIgnore)), nil
+	}
+
+	kyma, err := getKymaCR(params)
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	modules, _, _ := unstructured.NestedSlice(kyma.Object, "spec", "modules")
+	if index := specModuleIndex(modules, moduleName); index >= 0 {
+		return api.NewToolCallResult("", fmt.Errorf("module %s is already enabled, use kyma_module_set_channel to switch its channel", moduleName)), nil
+	}
+
+	effectiveChannel := channel
+	if effectiveChannel == "" {
+		effectiveChannel, _, _ = unstructured.NestedString(kyma.Object, "spec", "channel")
+	}
+	if err := validateModuleChannel(listModuleCatalog(params, kyma), moduleName, effectiveChannel); err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+
+	module := map[string]any{"name": moduleName}
+	if channel != "" {
+		module["channel"] = channel
+	}
+	if policy != "" {
+		module["customResourcePolicy"] = policy
+	}
+	return patchKymaModules(params, kyma, append(modules, module), fmt.Sprintf("module %s enabled", moduleName))
+}
+
+func kymaModuleDisable(params api.ToolHandlerParams) (*api.ToolCallResult, error) {
+	moduleName, err := common.GetRequiredString(params.GetArguments(), "module")
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+
+	kyma, err := getKymaCR(params)
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	modules, _, _ := unstructured.NestedSlice(kyma.Object, "spec", "modules")
+	index := specModuleIndex(modules, moduleName)
+	if index < 0 {
+		return api.NewToolCallResult("", fmt.Errorf("module %s is not enabled in the Kyma CR", moduleName)), nil
+	}
+
+	summary := fmt.Sprintf("module %s disabled", moduleName)
+	if policy, _ := modules[index].(map[string]any)["customResourcePolicy"].(string); policy != customResourcePolicyIgnore {
+		summary += ", its module CR and the resources it manages are deleted by the lifecycle manager"
+	}
+	return patchKymaModules(params, kyma, slices.Delete(modules, index, index+1), summary)
+}
+
+func kymaModuleSetChannel(params api.ToolHandlerParams) (*api.ToolCallResult, error) {
+	args := params.GetArguments()
+	moduleName, err := common.GetRequiredString(args, "module")
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	channel, err := common.GetRequiredString(args, "channel")
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+
+	kyma, err := getKymaCR(params)
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	modules, _, _ := unstructured.NestedSlice(kyma.Object, "spec", "modules")
+	index := specModuleIndex(modules, moduleName)
+	if index < 0 {
+		return api.NewToolCallResult("", fmt.Errorf("module %s is not enabled in the Kyma CR, use kyma_module_enable", moduleName)), nil
+	}
+	if err := validateModuleChannel(listModuleCatalog(params, kyma), moduleName, channel); err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+
+	module := modules[index].(map[string]any)
+	if current, _ := module["channel"].(string); current == channel {
+		return api.NewToolCallResult("", fmt.Errorf("module %s already uses channel %s", moduleName, channel)), nil
+	}
+	module["channel"] = channel
+	return patchKymaModules(params, kyma, modules, fmt.Sprintf("module %s switched to channel %s", moduleName, channel))
+}
+
+// specModuleIndex returns the index of the module in spec.modules, or -1.
+func specModuleIndex(modules []any, moduleName string) int {
+	return slices.IndexFunc(modules, func(module any) bool {
+		moduleMap, ok := module.(map[string]any)
+		return ok && moduleMap["name"] == moduleName
+	})
+}
+
+// validateModuleChannel checks that the module is available in the channel according to the module catalog.
+func validateModuleChannel(catalog *moduleCatalog, moduleName, channel string) error {
+	if !catalog.hasModule(moduleName) {
+		if len(catalog.errors) > 0 {
+			return fmt.Errorf("module %s cannot be validated: %s", moduleName, strings.Join(catalog.errors, "; "))
+		}
+		return fmt.Errorf("module %s is not available, no ModuleTemplate found (available modules: %s)", moduleName, strings.Join(catalog.moduleNames(), ", "))
+	}
+	if channel == "" {
+		channel = defaultModuleChannel
+	}
+	if _, ok := catalog.channelVersion(moduleName, channel); !ok && len(catalog.channels[moduleName]) > 0 {
+		return fmt.Errorf("channel %s is not available for module %s (available channels: %s)", channel, moduleName, strings.Join(catalog.channelNames(moduleName), ", "))
+	}
+	return nil
+}
+
+// patchKymaModules replaces spec.modules of the Kyma CR with a JSON patch guarded by the resourceVersion of
+// the read Kyma CR, so that concurrent changes are never overwritten. In dry-run mode the patch is only
+// validated by the API server. The resulting change of spec.modules is returned as a diff.
+func patchKymaModules(params api.ToolHandlerParams, kyma *unstructured.Unstructured, modules []any, summary string) (*api.ToolCallResult, error) {
+	dryRun, err := common.GetOptionalBool(params.GetArguments(), "dryRun", false)
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+
+	patch, err := json.Marshal([]map[string]any{
+		{"op": "test", "path": "/metadata/resourceVersion", "value": kyma.GetResourceVersion()},
+		{"op": "add", "path": "/spec/modules", "value": modules},
+	})
+	if err != nil {
+		return api.NewToolCallResult("", fmt.Errorf("failed to create the Kyma CR patch: %w", err)), nil
+	}
+
+	gvk := kyma.GroupVersionKind()
+	mapping, err := params.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return api.NewToolCallResult("", fmt.Errorf("failed to map Kyma CR resource: %w", err)), nil
+	}
+	options := metav1.PatchOptions{}
+	if dryRun {
+		options.DryRun = []string{metav1.DryRunAll}
+	}
+	patched, err := params.DynamicClient().Resource(mapping.Resource).Namespace(kyma.GetNamespace()).
+		Patch(params.Context, kyma.GetName(), types.JSONPatchType, patch, options)
+	if err != nil {
+		mcplog.HandleK8sError(params.Context, err, "kyma resource patch")
+		if apierrors.IsConflict(err) || apierrors.IsInvalid(err) {
+			return api.NewToolCallResult("", fmt.Errorf("failed to patch Kyma CR, it may have been modified concurrently, retry: %w", err)), nil
+		}
+		return api.NewToolCallResult("", fmt.Errorf("failed to patch Kyma CR: %w", err)), nil
+	}
+
+	diff, err := specModulesDiff(kyma, patched)
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	header := "# Applied: " + summary
+	if dryRun {
+		header = "# Dry run (not applied): " + summary
+	}
+	return api.NewToolCallResult(strings.Join([]string{header, "# spec.modules diff", diff}, "\n"), nil), nil
+}
+
+func specModulesDiff(before, after *unstructured.Unstructured) (string, error) {
+	beforeModules, _, _ := unstructured.NestedSlice(before.Object, "spec", "modules")
+	afterModules, _, _ := unstructured.NestedSlice(after.Object, "spec", "modules")
+	beforeYaml, err := output.MarshalYaml(beforeModules)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal modules: %w", err)
+	}
+	afterYaml, err := output.MarshalYaml(afterModules)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal modules: %w", err)
+	}
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(beforeYaml),
+		B:        difflib.SplitLines(afterYaml),
+		FromFile: "spec.modules (current)",
+		ToFile:   "spec.modules (new)",
+		Context:  3,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to diff modules: %w", err)
+	}
+	if diff == "" {
+		return "# No changes", nil
+	}
+	return strings.TrimSpace(diff), nil
+}
@@ -0,0 +1,323 @@
+package kyma
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/containers/kubernetes-mcp-server/pkg/api"
+	"github.com/containers/kubernetes-mcp-server/pkg/mcplog"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/mfaizanse/ext-kyma-mcp/pkg/toolsets/common"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/ptr"
+)
+
+// moduleResource identifies the module CR or the manager of a module.
+type moduleResource struct {
+	APIVersion string
+	Kind       string
+	Name       string
+	Namespace  string
+}
+
+func initModuleDiagnose() []api.ServerTool {
+	properties := kymaCRProperties()
+	properties["module"] = &jsonschema.Schema{
+		Type:        "string",
+		Description: "Name of the module to diagnose (e.g. serverless, telemetry, api-gateway, istio)",
+	}
+	return []api.ServerTool{
+		{
+			Tool: api.Tool{
+				Name: "kyma_module_diagnose",
+				Description: "Diagnose a Kyma module: its state in the Kyma CR, its module CR (e.g. Serverless, Telemetry, APIGateway, Istio) status and conditions, " +
+					"its manager Deployment, the non-ready manager pods and the recent warning events. Returns a markdown report highlighting the most likely root cause.",
+				InputSchema: &jsonschema.Schema{
+					Type:       "object",
+					Properties: properties,
+					Required:   []string{"module"},
+				},
+				Annotations: api.ToolAnnotations{
+					Title:           "Kyma: Module Diagnose",
+					ReadOnlyHint:    ptr.To(true),
+					DestructiveHint: ptr.To(false),
+					OpenWorldHint:   ptr.To(true),
+				},
+			},
+			Handler: kymaModuleDiagnose,
+		},
+	}
+}
+
+// moduleDiagnosis collects the report sections and the findings, the first finding is the likely root cause.
+type moduleDiagnosis struct {
+	findings []string
+	sections []string
+}
+
+func (d *moduleDiagnosis) finding(format string, a ...any) {
+	d.findings = append(d.findings, fmt.Sprintf(format, a...))
+}
+
+func (d *moduleDiagnosis) section(title string, lines ...string) {
+	d.sections = append(d.sections, "## "+title+"\n"+strings.Join(lines, "\n"))
+}
+
+func kymaModuleDiagnose(params api.ToolHandlerParams) (*api.ToolCallResult, error) {
+	moduleName, err := common.GetRequiredString(params.GetArguments(), "module")
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	kyma, err := getKymaCR(params)
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+
+	diagnosis := &moduleDiagnosis{}
+	catalog := listModuleCatalog(params, kyma)
+	var module *moduleStatus
+	for _, m := range joinKymaModules(kyma, catalog) {
+		if m.Name == moduleName {
+			module = m
+		}
+	}
+	if module == nil {
+		diagnosis.finding("module %s is not enabled in the Kyma CR %s/%s, enable it with kyma_module_enable", moduleName, kyma.GetNamespace(), kyma.GetName())
+		diagnosis.section("Kyma module", "- not enabled")
+		return api.NewToolCallResult(diagnosis.report(moduleName), nil), nil
+	}
+	diagnosis.diagnoseKymaModule(module)
+
+	template := catalog.moduleTemplate(module)
+	moduleCR := resolveModuleCR(kyma, template, moduleName)
+	diagnosis.diagnoseModuleCR(params, module, moduleCR)
+
+	deployment := diagnosis.diagnoseManager(params, template, moduleName)
+
+	// warning events of the module CR and of the manager (deployment, replica sets and pods)
+	prefixes := make([]string, 0, 2)
+	if deployment != nil {
+		prefixes = append(prefixes, deployment.Name)
+	}
+	if moduleCR != nil {
+		prefixes = append(prefixes, moduleCR.Name)
+	}
+	namespaces := []string{defaultKymaNamespace}
+	if moduleCR != nil && moduleCR.Namespace != "" && moduleCR.Namespace != defaultKymaNamespace {
+		namespaces = append(namespaces, moduleCR.Namespace)
+	}
+	for _, namespace := range namespaces {
+		events, err := common.ListWarningEvents(params, namespace, func(event map[string]any) bool {
+			involved, ok := common.EventInvolvedObject(event)
+			return ok && slices.ContainsFunc(prefixes, func(prefix string) bool { return strings.HasPrefix(involved["Name"], prefix) })
+		})
+		if err != nil {
+			events = fmt.Sprintf("# Warning events unavailable: %v", err)
+		} else if !strings.HasPrefix(events, "#") {
+			diagnosis.finding("warning events were recorded in %s, see the warning events section", namespace)
+		}
+		diagnosis.section(fmt.Sprintf("Warning events in %s (YAML)", namespace), events)
+	}
+
+	return api.NewToolCallResult(diagnosis.report(moduleName), nil), nil
+}
+
+func (d *moduleDiagnosis) diagnoseKymaModule(module *moduleStatus) {
+	lines := []string{
+		"- channel: " + common.ValueOrDash(module.Channel),
+		"- desired version: " + common.ValueOrDash(module.DesiredVersion),
+		"- installed version: " + common.ValueOrDash(module.InstalledVersion),
+		"- state: " + common.ValueOrDash(module.State),
+	}
+	for _, note := range module.Notes {
+		lines = append(lines, "- note: "+note)
+	}
+	switch module.State {
+	case "Error":
+		d.finding("the lifecycle manager reports the module in Error state: %s", strings.Join(module.Notes, "; "))
+	case "":
+		d.finding("the module is not reported in the Kyma CR status, check the Kyma CR state and the lifecycle manager")
+	}
+	d.section("Kyma module", lines...)
+}
+
+// resolveModuleCR resolves the module CR from the Kyma CR status, or from the default CR of the ModuleTemplate.
+func resolveModuleCR(kyma *unstructured.Unstructured, template *unstructured.Unstructured, moduleName string) *moduleResource {
+	statusModules, _, _ := unstructured.NestedSlice(kyma.Object, "status", "modules")
+	for _, statusModule := range statusModules {
+		statusMap, ok := statusModule.(map[string]any)
+		if !ok || statusMap["name"] != moduleName {
+			continue
+		}
+		resource, ok := statusMap["resource"].(map[string]any)
+		if !ok {
+			break
+		}
+		ret := &moduleResource{}
+		ret.APIVersion, _ = resource["apiVersion"].(string)
+		ret.Kind, _ = resource["kind"].(string)
+		ret.Name, _, _ = unstructured.NestedString(resource, "metadata", "name")
+		ret.Namespace, _, _ = unstructured.NestedString(resource, "metadata", "namespace")
+		if ret.Kind != "" && ret.Name != "" {
+			return ret
+		}
+	}
+	if template == nil {
+		return nil
+	}
+	data, ok, _ := unstructured.NestedMap(template.Object, "spec", "data")
+	if !ok {
+		return nil
+	}
+	defaultCR := &unstructured.Unstructured{Object: data}
+	if defaultCR.GetKind() == "" || defaultCR.GetName() == "" {
+		return nil
+	}
+	return &moduleResource{APIVersion: defaultCR.GetAPIVersion(), Kind: defaultCR.GetKind(), Name: defaultCR.GetName(), Namespace: defaultCR.GetNamespace()}
+}
+
+func (d *moduleDiagnosis) diagnoseModuleCR(params api.ToolHandlerParams, module *moduleStatus, moduleCR *moduleResource) {
+	if moduleCR == nil {
+		if module.CustomResourcePolicy == customResourcePolicyIgnore {
+			d.finding("the module uses the Ignore customResourcePolicy and no module CR is known, create the module CR")
+		}
+		d.section("Module CR", "- unknown, neither reported in the Kyma CR status nor defined by the ModuleTemplate")
+		return
+	}
+
+	title := fmt.Sprintf("Module CR %s %s/%s", moduleCR.Kind, common.ValueOrDash(moduleCR.Namespace), moduleCR.Name)
+	resources, err := getModuleCRs(params, moduleCR)
+	if err != nil {
+		d.finding("the module CR %s %s cannot be read: %v", moduleCR.Kind, moduleCR.Name, err)
+		d.section(title, fmt.Sprintf("- unavailable: %v", err))
+		return
+	}
+	resource := &resources[0]
+	if resource.GetName() != moduleCR.Name || resource.GetNamespace() != moduleCR.Namespace {
+		title = fmt.Sprintf("Module CR %s %s/%s", moduleCR.Kind, common.ValueOrDash(resource.GetNamespace()), resource.GetName())
+	}
+
+	state, _, _ := unstructured.NestedString(resource.Object, "status", "state")
+	lines := []string{"- apiVersion: " + resource.GetAPIVersion(), "- state: " + common.ValueOrDash(state)}
+	if state == "Error" || state == "Warning" {
+		d.finding("the module CR %s %s is in %s state", resource.GetKind(), resource.GetName(), state)
+	}
+	for _, condition := range common.GetConditions(resource) {
+		lines = append(lines, fmt.Sprintf("- condition %s=%s (%s): %s", condition.Type, condition.Status, common.ValueOrDash(condition.Reason), common.ValueOrDash(condition.Message)))
+		if condition.Status == string(metav1.ConditionFalse) {
+			d.finding("condition %s of the module CR is False (%s): %s", condition.Type, common.ValueOrDash(condition.Reason), common.ValueOrDash(condition.Message))
+		}
+	}
+	d.section(title, lines...)
+}
+
+// diagnoseManager reports the manager Deployment of the module and its non-ready pods.
+func (d *moduleDiagnosis) diagnoseManager(params api.ToolHandlerParams, template *unstructured.Unstructured, moduleName string) *appsv1.Deployment {
+	deployment, namespace, err := findManagerDeployment(params, template, moduleName)
+	if err != nil {
+		d.section("Manager Deployment", fmt.Sprintf("- unavailable: %v", err))
+		return nil
+	}
+	if deployment == nil {
+		d.finding("no manager Deployment of the module was found in %s", namespace)
+		d.section("Manager Deployment", "- not found")
+		return nil
+	}
+
+	lines := []string{
+		fmt.Sprintf("- name: %s/%s", deployment.Namespace, deployment.Name),
+		fmt.Sprintf("- replicas: %d desired, %d ready, %d available", ptr.Deref(deployment.Spec.Replicas, 1), deployment.Status.ReadyReplicas, deployment.Status.AvailableReplicas),
+	}
+	for _, condition := range deployment.Status.Conditions {
+		lines = append(lines, fmt.Sprintf("- condition %s=%s (%s): %s", condition.Type, condition.Status, common.ValueOrDash(condition.Reason), common.ValueOrDash(condition.Message)))
+	}
+	if deployment.Status.AvailableReplicas < ptr.Deref(deployment.Spec.Replicas, 1) {
+		d.finding("the manager Deployment %s has %d of %d replicas available", deployment.Name, deployment.Status.AvailableReplicas, ptr.Deref(deployment.Spec.Replicas, 1))
+	}
+	d.section("Manager Deployment", lines...)
+
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return deployment
+	}
+	pods, err := params.CoreV1().Pods(deployment.Namespace).List(params.Context, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		mcplog.HandleK8sError(params.Context, err, "pods listing")
+		d.section("Non-ready manager pods", fmt.Sprintf("- unavailable: %v", err))
+		return deployment
+	}
+	var podLines []string
+	for _, pod := range pods.Items {
+		if problem := common.PodProblem(&pod); problem != "" {
+			podLines = append(podLines, fmt.Sprintf("- %s: %s", pod.Name, problem))
+			d.finding("manager pod %s is not ready: %s", pod.Name, problem)
+		}
+	}
+	if len(podLines) == 0 {
+		podLines = []string{"- all manager pods are ready"}
+	}
+	d.section("Non-ready manager pods", podLines...)
+	return deployment
+}
+
+// findManagerDeployment finds the manager Deployment declared by the ModuleTemplate, or the Deployment named after
+// the module (e.g. serverless-operator, telemetry-manager, istio-controller-manager) in the manager namespace of the
+// ModuleTemplate, kyma-system by default. The searched namespace is returned with the Deployment.
+func findManagerDeployment(params api.ToolHandlerParams, template *unstructured.Unstructured, moduleName string) (*appsv1.Deployment, string, error) {
+	namespace := defaultKymaNamespace
+	if template != nil {
+		kind, _, _ := unstructured.NestedString(template.Object, "spec", "manager", "kind")
+		name, _, _ := unstructured.NestedString(template.Object, "spec", "manager", "name")
+		if managerNamespace, _, _ := unstructured.NestedString(template.Object, "spec", "manager", "namespace"); managerNamespace != "" {
+			namespace = managerNamespace
+		}
+		if kind == "Deployment" && name != "" {
+			deployment, err := params.AppsV1().Deployments(namespace).Get(params.Context, name, metav1.GetOptions{})
+			if err == nil {
+				return deployment, namespace, nil
+			}
+			mcplog.HandleK8sError(params.Context, err, "deployment access")
+		}
+	}
+
+	deployments, err := params.AppsV1().Deployments(namespace).List(params.Context, metav1.ListOptions{})
+	if err != nil {
+		mcplog.HandleK8sError(params.Context, err, "deployments listing")
+		return nil, namespace, err
+	}
+	var ret *appsv1.Deployment
+	for i := range deployments.Items {
+		deployment := &deployments.Items[i]
+		if !strings.Contains(deployment.Name, moduleName) {
+			continue
+		}
+		for _, suffix := range []string{"-manager", "-operator", "-controller"} {
+			if strings.HasSuffix(deployment.Name, suffix) {
+				return deployment, namespace, nil
+			}
+		}
+		if ret == nil {
+			ret = deployment
+		}
+	}
+	return ret, namespace, nil
+}
+
+func (d *moduleDiagnosis) report(moduleName string) string {
+	lines := []string{"# Kyma module diagnosis: " + moduleName, "## Likely root cause"}
+	if len(d.findings) == 0 {
+		lines = append(lines, "No problem found, the module, its CR and its manager are healthy.")
+	} else {
+		lines = append(lines, d.findings[0])
+		if len(d.findings) > 1 {
+			lines = append(lines, "", "Other findings:")
+			for _, finding := range d.findings[1:] {
+				lines = append(lines, "- "+finding)
+			}
+		}
+	}
+	return strings.Join(append(lines, d.sections...), "\n")
+}
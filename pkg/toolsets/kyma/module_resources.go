@@ -0,0 +1,129 @@
+package kyma
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/containers/kubernetes-mcp-server/pkg/api"
+	"github.com/containers/kubernetes-mcp-server/pkg/kubernetes"
+	"github.com/containers/kubernetes-mcp-server/pkg/mcplog"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/mfaizanse/ext-kyma-mcp/pkg/toolsets/common"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+)
+
+func initModuleResources() []api.ServerTool {
+	return []api.ServerTool{
+		{
+			Tool: api.Tool{
+				Name: "kyma_module_resources_status",
+				Description: "List the module CRs (e.g. Serverless, Telemetry, APIGateway, Eventing, BtpOperator) of the modules of the Kyma CR as a table: kind, apiVersion, name, state and conditions. " +
+					"The module CRs are found from the Kyma CR status and the ModuleTemplate default CR (spec.data), their served apiVersion is resolved through discovery.",
+				InputSchema: &jsonschema.Schema{
+					Type:       "object",
+					Properties: kymaCRProperties(),
+				},
+				Annotations: api.ToolAnnotations{
+					Title:           "Kyma: Module Resources Status",
+					ReadOnlyHint:    ptr.To(true),
+					DestructiveHint: ptr.To(false),
+					OpenWorldHint:   ptr.To(true),
+				},
+			},
+			Handler: kymaModuleResourcesStatus,
+		},
+	}
+}
+
+func kymaModuleResourcesStatus(params api.ToolHandlerParams) (*api.ToolCallResult, error) {
+	kyma, err := getKymaCR(params)
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	catalog := listModuleCatalog(params, kyma)
+	modules := joinKymaModules(kyma, catalog)
+	if len(modules) == 0 {
+		return api.NewToolCallResult("# No modules are enabled in the Kyma CR", nil), nil
+	}
+
+	buf := new(bytes.Buffer)
+	w := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "MODULE\tKIND\tAPIVERSION\tNAMESPACE\tNAME\tSTATE\tCONDITIONS")
+	var notes []string
+	for _, module := range modules {
+		moduleCR := resolveModuleCR(kyma, catalog.moduleTemplate(module), module.Name)
+		if moduleCR == nil {
+			_, _ = fmt.Fprintf(w, "%s\t-\t-\t-\t-\t-\t-\n", module.Name)
+			notes = append(notes, fmt.Sprintf("- %s: no module CR is known, neither reported in the Kyma CR status nor defined by the ModuleTemplate", module.Name))
+			continue
+		}
+		resources, err := getModuleCRs(params, moduleCR)
+		if err != nil {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t-\t-\n",
+				module.Name, moduleCR.Kind, moduleCR.APIVersion, common.ValueOrDash(moduleCR.Namespace), moduleCR.Name)
+			notes = append(notes, fmt.Sprintf("- %s: %v", module.Name, err))
+			continue
+		}
+		for i := range resources {
+			resource := &resources[i]
+			state, _, _ := unstructured.NestedString(resource.Object, "status", "state")
+			conditions := common.GetConditions(resource)
+			summary := make([]string, 0, len(conditions))
+			for _, condition := range conditions {
+				summary = append(summary, condition.Type+"="+condition.Status)
+				if condition.Status != string(metav1.ConditionTrue) {
+					notes = append(notes, fmt.Sprintf("- %s: condition %s=%s of %s %s (%s): %s", module.Name, condition.Type, condition.Status,
+						resource.GetKind(), resource.GetName(), common.ValueOrDash(condition.Reason), common.ValueOrDash(condition.Message)))
+				}
+			}
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				module.Name, resource.GetKind(), resource.GetAPIVersion(), common.ValueOrDash(resource.GetNamespace()), resource.GetName(),
+				common.ValueOrDash(state), common.ValueOrDash(strings.Join(summary, ",")))
+		}
+	}
+	_ = w.Flush()
+
+	lines := []string{fmt.Sprintf("# Module CRs of the Kyma %s/%s", kyma.GetNamespace(), kyma.GetName()), strings.TrimSpace(buf.String())}
+	for _, catalogErr := range catalog.errors {
+		notes = append(notes, "- "+catalogErr)
+	}
+	if len(notes) > 0 {
+		lines = append(lines, "# Notes")
+		lines = append(lines, notes...)
+	}
+	return api.NewToolCallResult(strings.Join(lines, "\n"), nil), nil
+}
+
+// getModuleCRs gets the module CR in the apiVersion served by the cluster, resolved through discovery since the
+// Kyma CR status or the ModuleTemplate may reference an older version. If the CR does not exist under its default
+// name (e.g. with the Ignore customResourcePolicy the CR is created by the user), all the CRs of the kind are returned.
+func getModuleCRs(params api.ToolHandlerParams, moduleCR *moduleResource) ([]unstructured.Unstructured, error) {
+	gv, err := schema.ParseGroupVersion(moduleCR.APIVersion)
+	if err != nil {
+		return nil, fmt.Errorf("invalid apiVersion %q of %s: %w", moduleCR.APIVersion, moduleCR.Kind, err)
+	}
+	gvk, err := common.ResolveGroupVersionKind(params.DiscoveryClient(), gv.Group, moduleCR.Kind)
+	if err != nil {
+		return nil, err
+	}
+
+	resource, err := kubernetes.NewCore(params).ResourcesGet(params.Context, &gvk, moduleCR.Namespace, moduleCR.Name)
+	if err == nil {
+		return []unstructured.Unstructured{*resource}, nil
+	}
+	mcplog.HandleK8sError(params.Context, err, "module resource access")
+	if !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to get %s %s: %w", moduleCR.Kind, moduleCR.Name, err)
+	}
+	resources, listErr := common.ListResources(params, gvk, "")
+	if listErr != nil || len(resources) == 0 {
+		return nil, fmt.Errorf("%s %s not found: %w", moduleCR.Kind, moduleCR.Name, err)
+	}
+	return resources, nil
+}
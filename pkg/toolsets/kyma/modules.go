@@ -0,0 +1,348 @@
+package kyma
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/containers/kubernetes-mcp-server/pkg/api"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/mfaizanse/ext-kyma-mcp/pkg/toolsets/common"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	moduleTemplateKind    = "ModuleTemplate"
+	moduleReleaseMetaKind = "ModuleReleaseMeta"
+
+	// moduleNameLabel and moduleVersionAnnotation identify the module of legacy (channel based) ModuleTemplates.
+	moduleNameLabel         = "operator.kyma-project.io/module-name"
+	moduleVersionAnnotation = "operator.kyma-project.io/module-version"
+
+	defaultModuleChannel        = "regular"
+	defaultCustomResourcePolicy = "CreateAndDelete"
+)
+
+func initModules() []api.ServerTool {
+	return []api.ServerTool{
+		{
+			Tool: api.Tool{
+				Name: "kyma_modules_list",
+				Description: "List the Kyma modules of the Kyma CR as a table: name, channel, desired vs installed version, state (Ready/Processing/Error/Warning/Deleting), managed flag and customResourcePolicy. " +
+					"The desired version is resolved from the ModuleReleaseMeta and ModuleTemplate resources of the module channel. " +
+					"This is the first thing to look at when a Kyma runtime misbehaves.",
+				InputSchema: &jsonschema.Schema{
+					Type:       "object",
+					Properties: kymaCRProperties(),
+				},
+				Annotations: api.ToolAnnotations{
+					Title:           "Kyma: Modules List",
+					ReadOnlyHint:    ptr.To(true),
+					DestructiveHint: ptr.To(false),
+					OpenWorldHint:   ptr.To(true),
+				},
+			},
+			Handler: kymaModulesList,
+		},
+	}
+}
+
+// moduleStatus is a module of the Kyma CR, joined from its spec, its status and the module catalog.
+type moduleStatus struct {
+	Name                 string
+	Channel              string
+	DesiredVersion       string
+	InstalledVersion     string
+	State                string
+	Managed              *bool
+	CustomResourcePolicy string
+	Notes                []string
+}
+
+// moduleCatalog holds the module versions available per channel, read from the ModuleReleaseMeta and the
+// ModuleTemplate resources.
+type moduleCatalog struct {
+	// channels maps a module to its channel versions
+	channels map[string]map[string]string
+	// versions maps a module to the versions of its ModuleTemplates
+	versions map[string][]string
+	// templates are the listed ModuleTemplates
+	templates []unstructured.Unstructured
+	// errors are the catalog resources that could not be listed
+	errors []string
+}
+
+func kymaModulesList(params api.ToolHandlerParams) (*api.ToolCallResult, error) {
+	kyma, err := getKymaCR(params)
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+
+	catalog := listModuleCatalog(params, kyma)
+	modules := joinKymaModules(kyma, catalog)
+
+	kymaState, _, _ := unstructured.NestedString(kyma.Object, "status", "state")
+	kymaChannel, _, _ := unstructured.NestedString(kyma.Object, "spec", "channel")
+	lines := []string{
+		fmt.Sprintf("# Kyma %s/%s: state=%s, channel=%s", kyma.GetNamespace(), kyma.GetName(), common.ValueOrDash(kymaState), common.ValueOrDash(kymaChannel)),
+	}
+	if len(modules) == 0 {
+		lines = append(lines, "# No modules are enabled in the Kyma CR")
+	} else {
+		lines = append(lines, formatModulesTable(modules))
+	}
+
+	var notes []string
+	for _, module := range modules {
+		for _, note := range module.Notes {
+			notes = append(notes, fmt.Sprintf("- %s: %s", module.Name, note))
+		}
+	}
+	for _, catalogErr := range catalog.errors {
+		notes = append(notes, "- "+catalogErr)
+	}
+	if len(notes) > 0 {
+		lines = append(lines, "# Notes")
+		lines = append(lines, notes...)
+	}
+	return api.NewToolCallResult(strings.Join(lines, "\n"), nil), nil
+}
+
+// listModuleCatalog reads the ModuleReleaseMeta and ModuleTemplate resources served next to the Kyma CR.
+// Listing failures (e.g. missing RBAC) are recorded in the catalog instead of failing the caller.
+func listModuleCatalog(params api.ToolHandlerParams, kyma *unstructured.Unstructured) *moduleCatalog {
+	catalog := &moduleCatalog{
+		channels: make(map[string]map[string]string),
+		versions: make(map[string][]string),
+	}
+	gv := kyma.GroupVersionKind().GroupVersion()
+
+	releaseMetas, err := common.ListResources(params, gv.WithKind(moduleReleaseMetaKind), kyma.GetNamespace())
+	if err != nil {
+		catalog.errors = append(catalog.errors, fmt.Sprintf("ModuleReleaseMeta resources unavailable: %v", err))
+	}
+	for _, releaseMeta := range releaseMetas {
+		moduleName, _, _ := unstructured.NestedString(releaseMeta.Object, "spec", "moduleName")
+		if moduleName == "" {
+			continue
+		}
+		channels, _, _ := unstructured.NestedSlice(releaseMeta.Object, "spec", "channels")
+		for _, channel := range channels {
+			channelMap, ok := channel.(map[string]any)
+			if !ok {
+				continue
+			}
+			name, _ := channelMap["channel"].(string)
+			version, _ := channelMap["version"].(string)
+			catalog.addChannel(moduleName, name, version)
+		}
+	}
+
+	catalog.templates, err = common.ListResources(params, gv.WithKind(moduleTemplateKind), kyma.GetNamespace())
+	if err != nil {
+		catalog.errors = append(catalog.errors, fmt.Sprintf("ModuleTemplate resources unavailable: %v", err))
+	}
+	for i := range catalog.templates {
+		template := &catalog.templates[i]
+		moduleName := templateModuleName(template)
+		if moduleName == "" {
+			continue
+		}
+		version := templateVersion(template)
+		if version != "" && !slices.Contains(catalog.versions[moduleName], version) {
+			catalog.versions[moduleName] = append(catalog.versions[moduleName], version)
+		}
+		// legacy templates are bound to a channel instead of being referenced by a ModuleReleaseMeta
+		if channel, _, _ := unstructured.NestedString(template.Object, "spec", "channel"); channel != "" {
+			if _, exists := catalog.channels[moduleName][channel]; !exists {
+				catalog.addChannel(moduleName, channel, version)
+			}
+		}
+	}
+	return catalog
+}
+
+// moduleTemplate returns the ModuleTemplate of the installed (or desired) version of the module, if any.
+func (c *moduleCatalog) moduleTemplate(module *moduleStatus) *unstructured.Unstructured {
+	version := module.InstalledVersion
+	if version == "" {
+		version = module.DesiredVersion
+	}
+	var ret *unstructured.Unstructured
+	for i := range c.templates {
+		template := &c.templates[i]
+		if templateModuleName(template) != module.Name {
+			continue
+		}
+		channel, _, _ := unstructured.NestedString(template.Object, "spec", "channel")
+		if templateVersion(template) == version || (channel != "" && channel == module.Channel) {
+			return template
+		}
+		ret = template
+	}
+	return ret
+}
+
+// templateModuleName returns the module of the ModuleTemplate, from its spec or its legacy label.
+func templateModuleName(template *unstructured.Unstructured) string {
+	if moduleName, _, _ := unstructured.NestedString(template.Object, "spec", "moduleName"); moduleName != "" {
+		return moduleName
+	}
+	return template.GetLabels()[moduleNameLabel]
+}
+
+// templateVersion returns the module version of the ModuleTemplate, from its spec or its legacy annotation.
+func templateVersion(template *unstructured.Unstructured) string {
+	if version, _, _ := unstructured.NestedString(template.Object, "spec", "version"); version != "" {
+		return version
+	}
+	return template.GetAnnotations()[moduleVersionAnnotation]
+}
+
+func (c *moduleCatalog) addChannel(moduleName, channel, version string) {
+	if channel == "" {
+		return
+	}
+	if c.channels[moduleName] == nil {
+		c.channels[moduleName] = make(map[string]string)
+	}
+	c.channels[moduleName][channel] = version
+}
+
+// hasModule returns true if the module is known to the catalog.
+func (c *moduleCatalog) hasModule(moduleName string) bool {
+	return len(c.channels[moduleName]) > 0 || len(c.versions[moduleName]) > 0
+}
+
+// moduleNames returns the sorted names of the modules known to the catalog.
+func (c *moduleCatalog) moduleNames() []string {
+	ret := make([]string, 0, len(c.channels))
+	for name := range c.channels {
+		ret = append(ret, name)
+	}
+	for name := range c.versions {
+		if !slices.Contains(ret, name) {
+			ret = append(ret, name)
+		}
+	}
+	slices.Sort(ret)
+	return ret
+}
+
+// channelVersion returns the module version of the channel, if available.
+func (c *moduleCatalog) channelVersion(moduleName, channel string) (string, bool) {
+	version, ok := c.channels[moduleName][channel]
+	return version, ok
+}
+
+// channelNames returns the sorted channels of the module.
+func (c *moduleCatalog) channelNames(moduleName string) []string {
+	ret := make([]string, 0, len(c.channels[moduleName]))
+	for channel := range c.channels[moduleName] {
+		ret = append(ret, channel)
+	}
+	slices.Sort(ret)
+	return ret
+}
+
+// joinKymaModules joins the modules of the Kyma CR spec and status with the catalog, sorted by name.
+// Modules only present in the status (e.g. being removed) are included.
+func joinKymaModules(kyma *unstructured.Unstructured, catalog *moduleCatalog) []*moduleStatus {
+	kymaChannel, _, _ := unstructured.NestedString(kyma.Object, "spec", "channel")
+	if kymaChannel == "" {
+		kymaChannel = defaultModuleChannel
+	}
+
+	modules := make(map[string]*moduleStatus)
+	specModules, _, _ := unstructured.NestedSlice(kyma.Object, "spec", "modules")
+	for _, specModule := range specModules {
+		specMap, ok := specModule.(map[string]any)
+		if !ok {
+			continue
+		}
+		name, _ := specMap["name"].(string)
+		if name == "" {
+			continue
+		}
+		module := &moduleStatus{Name: name, Channel: kymaChannel, Managed: ptr.To(true), CustomResourcePolicy: defaultCustomResourcePolicy}
+		if channel, _ := specMap["channel"].(string); channel != "" {
+			module.Channel = channel
+		}
+		if managed, ok := specMap["managed"].(bool); ok {
+			module.Managed = ptr.To(managed)
+		}
+		if policy, _ := specMap["customResourcePolicy"].(string); policy != "" {
+			module.CustomResourcePolicy = policy
+		}
+		if version, _ := specMap["version"].(string); version != "" {
+			module.DesiredVersion = version
+		} else if version, ok := catalog.channelVersion(name, module.Channel); ok {
+			module.DesiredVersion = version
+		} else if catalog.hasModule(name) {
+			module.Notes = append(module.Notes, fmt.Sprintf("channel %q is not available, available channels: %s", module.Channel, strings.Join(catalog.channelNames(name), ", ")))
+		} else if len(catalog.errors) == 0 {
+			module.Notes = append(module.Notes, "no ModuleTemplate found for the module")
+		}
+		modules[name] = module
+	}
+
+	statusModules, _, _ := unstructured.NestedSlice(kyma.Object, "status", "modules")
+	for _, statusModule := range statusModules {
+		statusMap, ok := statusModule.(map[string]any)
+		if !ok {
+			continue
+		}
+		name, _ := statusMap["name"].(string)
+		if name == "" {
+			continue
+		}
+		module, ok := modules[name]
+		if !ok {
+			module = &moduleStatus{Name: name}
+			module.Notes = append(module.Notes, "not in the Kyma CR spec, the module is being removed")
+			modules[name] = module
+		}
+		if channel, _ := statusMap["channel"].(string); channel != "" && module.Channel == "" {
+			module.Channel = channel
+		}
+		module.InstalledVersion, _ = statusMap["version"].(string)
+		module.State, _ = statusMap["state"].(string)
+		if message, _ := statusMap["message"].(string); message != "" {
+			module.Notes = append(module.Notes, message)
+		}
+	}
+
+	ret := make([]*moduleStatus, 0, len(modules))
+	for _, module := range modules {
+		if module.State == "" && module.DesiredVersion != "" {
+			module.Notes = append(module.Notes, "not reported in the Kyma CR status yet")
+		}
+		if module.DesiredVersion != "" && module.InstalledVersion != "" && module.DesiredVersion != module.InstalledVersion {
+			module.Notes = append(module.Notes, fmt.Sprintf("installed version %s differs from the desired version %s", module.InstalledVersion, module.DesiredVersion))
+		}
+		ret = append(ret, module)
+	}
+	slices.SortFunc(ret, func(a, b *moduleStatus) int { return strings.Compare(a.Name, b.Name) })
+	return ret
+}
+
+func formatModulesTable(modules []*moduleStatus) string {
+	buf := new(bytes.Buffer)
+	w := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "NAME\tCHANNEL\tDESIRED\tINSTALLED\tSTATE\tMANAGED\tCR POLICY")
+	for _, module := range modules {
+		managed := "-"
+		if module.Managed != nil {
+			managed = strconv.FormatBool(*module.Managed)
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			module.Name, common.ValueOrDash(module.Channel), common.ValueOrDash(module.DesiredVersion), common.ValueOrDash(module.InstalledVersion),
+			common.ValueOrDash(module.State), managed, common.ValueOrDash(module.CustomResourcePolicy))
+	}
+	_ = w.Flush()
+	return strings.TrimSpace(buf.String())
+}
@@ -1,6 +1,8 @@
 package kyma
 
 import (
+	"slices"
+
 	"github.com/containers/kubernetes-mcp-server/pkg/api"
 	"github.com/containers/kubernetes-mcp-server/pkg/toolsets"
 )
@@ -18,7 +20,7 @@ func (t *Toolset) GetDescription() string {
 }
 
 func (t *Toolset) GetTools(api.Openshift) []api.ServerTool {
-	return initKyma()
+	return slices.Concat(initKyma(), initModules(), initModuleActions(), initModuleDiagnose(), initModuleResources())
 }
 
 func (t *Toolset) GetPrompts() []api.ServerPrompt {
@@ -10,12 +10,14 @@ import (
 	"github.com/containers/kubernetes-mcp-server/pkg/mcplog"
 	"github.com/containers/kubernetes-mcp-server/pkg/output"
 	"github.com/google/jsonschema-go/jsonschema"
+	extkubernetes "github.com/mfaizanse/ext-kyma-mcp/pkg/kubernetes"
 	"github.com/mfaizanse/ext-kyma-mcp/pkg/toolsets/common"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
 	"k8s.io/kubectl/pkg/metricsutil"
 	"k8s.io/utils/ptr"
 )
@@ -44,6 +46,23 @@ func initOverview() []api.ServerTool {
 			},
 			Handler: overviewClusterVersion,
 		},
+		{
+			Tool: api.Tool{
+				Name:        "overview_cluster_capabilities",
+				Description: "Get the flavour and optional components of the Kubernetes cluster (OpenShift, Kyma, Istio, metrics-server, Gardener shoot info)",
+				InputSchema: &jsonschema.Schema{
+					Type:       "object",
+					Properties: map[string]*jsonschema.Schema{},
+				},
+				Annotations: api.ToolAnnotations{
+					Title:           "Overview: Cluster Capabilities",
+					ReadOnlyHint:    ptr.To(true),
+					DestructiveHint: ptr.To(false),
+					OpenWorldHint:   ptr.To(true),
+				},
+			},
+			Handler: overviewClusterCapabilities,
+		},
 		{
 			Tool: api.Tool{
 				Name:        "overview_relevant_context",
@@ -96,6 +115,20 @@ func overviewClusterVersion(params api.ToolHandlerParams) (*api.ToolCallResult,
 	return api.NewToolCallResult(strings.TrimSpace(payload), nil), nil
 }
 
+func overviewClusterCapabilities(params api.ToolHandlerParams) (*api.ToolCallResult, error) {
+	capabilities, err := extkubernetes.GetClusterCapabilities(params.Context, params.KubernetesClient)
+	if err != nil {
+		return api.NewToolCallResult("", fmt.Errorf("failed to probe cluster capabilities: %w", err)), nil
+	}
+
+	payload, err := output.MarshalYaml(capabilities)
+	if err != nil {
+		return api.NewToolCallResult("", fmt.Errorf("failed to marshal cluster capabilities: %w", err)), nil
+	}
+
+	return api.NewToolCallResult(strings.TrimSpace(payload), nil), nil
+}
+
 func overviewRelevantContext(params api.ToolHandlerParams) (*api.ToolCallResult, error) {
 	args := params.GetArguments()
 	kind, err := common.GetRequiredString(args, "kind")
@@ -147,18 +180,29 @@ func clusterOverviewContext(params api.ToolHandlerParams) (*api.ToolCallResult,
 		return api.NewToolCallResult("", fmt.Errorf("failed to marshal pod list: %w", err)), nil
 	}
 
-	metrics, err := formatNodeMetrics(params, core)
+	// Optional components are skipped instead of failing the whole context
+	capabilities, err := extkubernetes.GetClusterCapabilities(params.Context, params.KubernetesClient)
 	if err != nil {
-		return api.NewToolCallResult("", err), nil
+		klog.V(2).Infof("failed to probe cluster capabilities: %v", err)
+		capabilities = nil
+	}
+
+	metrics := "# metrics-server not available, node metrics skipped"
+	if capabilities == nil || capabilities.MetricsServer {
+		if metrics, err = formatNodeMetrics(params, core); err != nil {
+			metrics = fmt.Sprintf("# Node metrics unavailable: %v", err)
+		}
 	}
 
-	warningEvents, err := listWarningEvents(params, "")
+	warningEvents, err := common.ListWarningEvents(params, "", nil)
 	if err != nil {
 		return api.NewToolCallResult("", err), nil
 	}
 
 	kymaStatus := "# Kyma CR Status (YAML)\n# Kyma CR not found or unavailable"
-	if status, statusErr := fetchKymaStatus(params); statusErr == nil && strings.TrimSpace(status) != "" {
+	if capabilities != nil && !capabilities.Kyma {
+		kymaStatus = "# Kyma CR Status (YAML)\n# Kyma is not installed in the cluster"
+	} else if status, statusErr := fetchKymaStatus(params); statusErr == nil && strings.TrimSpace(status) != "" {
 		kymaStatus = strings.Join([]string{"# Kyma CR Status (YAML)", status}, "\n")
 	}
 
@@ -175,7 +219,7 @@ func clusterOverviewContext(params api.ToolHandlerParams) (*api.ToolCallResult,
 }
 
 func namespaceOverviewContext(params api.ToolHandlerParams, namespace string) (*api.ToolCallResult, error) {
-	warningEvents, err := listWarningEvents(params, namespace)
+	warningEvents, err := common.ListWarningEvents(params, namespace, nil)
 	if err != nil {
 		return api.NewToolCallResult("", err), nil
 	}
@@ -203,7 +247,7 @@ func resourceOverviewContext(params api.ToolHandlerParams, apiVersion, kind, nam
 		return api.NewToolCallResult("", fmt.Errorf("failed to marshal resource: %w", err)), nil
 	}
 
-	resourceEvents, err := listEventsForResource(params, namespace, kind, name)
+	resourceEvents, err := common.ListEventsForResource(params, namespace, kind, name)
 	if err != nil {
 		return api.NewToolCallResult("", err), nil
 	}
@@ -217,52 +261,12 @@ func resourceOverviewContext(params api.ToolHandlerParams, apiVersion, kind, nam
 	return api.NewToolCallResult(content, nil), nil
 }
 
-func listWarningEvents(params api.ToolHandlerParams, namespace string) (string, error) {
-	events, err := kubernetes.NewCore(params).EventsList(params.Context, namespace)
-	if err != nil {
-		mcplog.HandleK8sError(params.Context, err, "events listing")
-		return "", fmt.Errorf("failed to list events: %w", err)
-	}
-	filtered := common.FilterEvents(events, func(event map[string]any) bool {
-		value, ok := event["Type"].(string)
-		return ok && strings.EqualFold(value, "Warning")
-	})
-	if len(filtered) == 0 {
-		return "# No warning events found", nil
-	}
-	yamlEvents, err := output.MarshalYaml(filtered)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal warning events: %w", err)
-	}
-	return strings.TrimSpace(yamlEvents), nil
-}
-
-func listEventsForResource(params api.ToolHandlerParams, namespace, kind, name string) (string, error) {
-	events, err := kubernetes.NewCore(params).EventsList(params.Context, namespace)
-	if err != nil {
-		mcplog.HandleK8sError(params.Context, err, "events listing")
-		return "", fmt.Errorf("failed to list events: %w", err)
-	}
-	filtered := common.FilterEvents(events, func(event map[string]any) bool {
-		involved, ok := event["InvolvedObject"].(map[string]string)
-		if !ok {
-			return false
-		}
-		return strings.EqualFold(involved["Kind"], kind) && strings.EqualFold(involved["Name"], name)
-	})
-	if len(filtered) == 0 {
-		return "# No events found for resource", nil
-	}
-	yamlEvents, err := output.MarshalYaml(filtered)
+func fetchKymaStatus(params api.ToolHandlerParams) (string, error) {
+	kymaCR, err := extkubernetes.GetKymaCR(params.Context, params.KubernetesClient)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal resource events: %w", err)
+		return "", err
 	}
-	return strings.TrimSpace(yamlEvents), nil
-}
-
-func fetchKymaStatus(params api.ToolHandlerParams) (string, error) {
-	gvk := &schema.GroupVersionKind{Group: "operator.kyma-project.io", Version: "v1beta2", Kind: "Kyma"}
-	resource, err := kubernetes.NewCore(params).ResourcesGet(params.Context, gvk, "kyma-system", "default")
+	resource, err := kubernetes.NewCore(params).ResourcesGet(params.Context, &kymaCR.GroupVersionKind, kymaCR.Namespace, kymaCR.Name)
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,331 @@
+package serverless
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/containers/kubernetes-mcp-server/pkg/api"
+	"github.com/containers/kubernetes-mcp-server/pkg/kubernetes"
+	"github.com/containers/kubernetes-mcp-server/pkg/mcplog"
+	"github.com/containers/kubernetes-mcp-server/pkg/output"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/mfaizanse/ext-kyma-mcp/pkg/toolsets/common"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	functionGroup = "serverless.kyma-project.io"
+	functionKind  = "Function"
+
+	conditionConfigurationReady = "ConfigurationReady"
+	conditionBuildReady         = "BuildReady"
+	conditionRunning            = "Running"
+)
+
+// functionRuntimes are the runtimes supported for inline Functions.
+var functionRuntimes = []any{"nodejs20", "nodejs22", "python312"}
+
+func initFunctions() []api.ServerTool {
+	return []api.ServerTool{
+		{
+			Tool: api.Tool{
+				Name:        "serverless_functions_list",
+				Description: "List the Kyma Serverless Functions with their runtime, source type, replicas and ConfigurationReady/BuildReady/Running conditions",
+				InputSchema: &jsonschema.Schema{
+					Type: "object",
+					Properties: map[string]*jsonschema.Schema{
+						"namespace": {
+							Type:        "string",
+							Description: "Namespace of the Functions (optional, lists the Functions of all namespaces if not provided)",
+						},
+					},
+				},
+				Annotations: api.ToolAnnotations{
+					Title:           "Serverless: Functions List",
+					ReadOnlyHint:    ptr.To(true),
+					DestructiveHint: ptr.To(false),
+					OpenWorldHint:   ptr.To(true),
+				},
+			},
+			Handler: functionsList,
+		},
+		{
+			Tool: api.Tool{
+				Name:        "serverless_function_get",
+				Description: "Get a Kyma Serverless Function with its conditions, and its inline source code and dependencies (or its git repository source)",
+				InputSchema: &jsonschema.Schema{
+					Type:       "object",
+					Properties: functionProperties(),
+					Required:   []string{"name"},
+				},
+				Annotations: api.ToolAnnotations{
+					Title:           "Serverless: Function Get",
+					ReadOnlyHint:    ptr.To(true),
+					DestructiveHint: ptr.To(false),
+					OpenWorldHint:   ptr.To(true),
+				},
+			},
+			Handler: functionGet,
+		},
+		{
+			Tool: api.Tool{
+				Name: "serverless_function_apply",
+				Description: "Create or update a Kyma Serverless Function with inline source code. " +
+					"An existing Function's source code, dependencies and runtime are replaced with the provided ones.",
+				InputSchema: &jsonschema.Schema{
+					Type: "object",
+					Properties: func() map[string]*jsonschema.Schema {
+						properties := functionProperties()
+						properties["runtime"] = &jsonschema.Schema{
+							Type:        "string",
+							Description: "Runtime of the Function",
+							Enum:        functionRuntimes,
+						}
+						properties["source"] = &jsonschema.Schema{
+							Type:        "string",
+							Description: "Source code of the Function (a handler.js exporting main for nodejs, a handler.py defining main for python)",
+						}
+						properties["dependencies"] = &jsonschema.Schema{
+							Type:        "string",
+							Description: "Dependencies of the Function (optional, the package.json content for nodejs or the requirements.txt content for python)",
+						}
+						return properties
+					}(),
+					Required: []string{"name", "runtime", "source"},
+				},
+				Annotations: api.ToolAnnotations{
+					Title:           "Serverless: Function Create or Update",
+					ReadOnlyHint:    ptr.To(false),
+					DestructiveHint: ptr.To(true),
+					IdempotentHint:  ptr.To(true),
+					OpenWorldHint:   ptr.To(true),
+				},
+			},
+			Handler: functionApply,
+		},
+	}
+}
+
+// functionProperties returns the input properties that select a Function.
+func functionProperties() map[string]*jsonschema.Schema {
+	return map[string]*jsonschema.Schema{
+		"namespace": {
+			Type:        "string",
+			Description: "Namespace of the Function (optional, defaults to the configured namespace)",
+		},
+		"name": {
+			Type:        "string",
+			Description: "Name of the Function",
+		},
+	}
+}
+
+func functionsList(params api.ToolHandlerParams) (*api.ToolCallResult, error) {
+	namespace, err := common.GetOptionalString(params.GetArguments(), "namespace")
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+
+	functionGVK, err := common.ResolveGroupVersionKind(params.DiscoveryClient(), functionGroup, functionKind)
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	functions, err := common.ListResources(params, functionGVK, namespace)
+	if err != nil {
+		return api.NewToolCallResult("", fmt.Errorf("failed to list Functions: %w", err)), nil
+	}
+	if len(functions) == 0 {
+		return api.NewToolCallResult("# No Functions found", nil), nil
+	}
+	sort.Slice(functions, func(i, j int) bool {
+		if functions[i].GetNamespace() != functions[j].GetNamespace() {
+			return functions[i].GetNamespace() < functions[j].GetNamespace()
+		}
+		return functions[i].GetName() < functions[j].GetName()
+	})
+
+	buf := new(bytes.Buffer)
+	w := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "NAMESPACE\tNAME\tRUNTIME\tSOURCE\tREPLICAS\tCONFIGURED\tBUILT\tRUNNING")
+	var problems []string
+	for i := range functions {
+		function := &functions[i]
+		conditions := common.GetConditions(function)
+		runtime, _, _ := unstructured.NestedString(function.Object, "spec", "runtime")
+		replicas, _, _ := unstructured.NestedInt64(function.Object, "status", "replicas")
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\t%s\t%s\n",
+			function.GetNamespace(), function.GetName(), common.ValueOrDash(runtime), functionSourceType(function), replicas,
+			conditions.Status(conditionConfigurationReady), conditions.Status(conditionBuildReady), conditions.Status(conditionRunning))
+		for _, condition := range conditions {
+			if condition.Status != "True" && condition.Message != "" {
+				problems = append(problems, fmt.Sprintf("- %s/%s %s (%s): %s",
+					function.GetNamespace(), function.GetName(), condition.Type, common.ValueOrDash(condition.Reason), condition.Message))
+			}
+		}
+	}
+	_ = w.Flush()
+
+	ret := strings.TrimSpace(buf.String())
+	if len(problems) > 0 {
+		ret += "\n\n# Conditions not ready\n" + strings.Join(problems, "\n")
+	}
+	return api.NewToolCallResult(ret, nil), nil
+}
+
+func functionGet(params api.ToolHandlerParams) (*api.ToolCallResult, error) {
+	function, err := getFunction(params)
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+
+	runtime, _, _ := unstructured.NestedString(function.Object, "spec", "runtime")
+	lines := []string{
+		fmt.Sprintf("# Function %s/%s", function.GetNamespace(), function.GetName()),
+		"- runtime: " + common.ValueOrDash(runtime),
+		"- source: " + functionSourceType(function),
+	}
+	for _, condition := range common.GetConditions(function) {
+		lines = append(lines, fmt.Sprintf("- condition %s=%s (%s): %s",
+			condition.Type, condition.Status, common.ValueOrDash(condition.Reason), common.ValueOrDash(condition.Message)))
+	}
+
+	if inline, ok, _ := unstructured.NestedMap(function.Object, "spec", "source", "inline"); ok {
+		source, _ := inline["source"].(string)
+		dependencies, _ := inline["dependencies"].(string)
+		lines = append(lines, "", "## Source", "```", strings.TrimRight(source, "\n"), "```")
+		if dependencies != "" {
+			lines = append(lines, "", "## Dependencies", "```", strings.TrimRight(dependencies, "\n"), "```")
+		}
+	}
+	if gitRepository, ok, _ := unstructured.NestedMap(function.Object, "spec", "source", "gitRepository"); ok {
+		marshalled, err := output.MarshalYaml(gitRepository)
+		if err != nil {
+			return api.NewToolCallResult("", fmt.Errorf("failed to marshal the git repository source: %w", err)), nil
+		}
+		lines = append(lines, "", "## Git repository (YAML)", strings.TrimSpace(marshalled))
+	}
+	return api.NewToolCallResult(strings.Join(lines, "\n"), nil), nil
+}
+
+func functionApply(params api.ToolHandlerParams) (*api.ToolCallResult, error) {
+	args := params.GetArguments()
+	namespace, err := common.GetOptionalString(args, "namespace")
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	name, err := common.GetRequiredString(args, "name")
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	runtime, err := common.GetRequiredString(args, "runtime")
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	source, err := common.GetRequiredString(args, "source")
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	dependencies, err := common.GetOptionalString(args, "dependencies")
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	if !isFunctionRuntime(runtime) {
+		return api.NewToolCallResult("", fmt.Errorf("unsupported runtime %s, supported runtimes: %v", runtime, functionRuntimes)), nil
+	}
+
+	functionGVK, err := common.ResolveGroupVersionKind(params.DiscoveryClient(), functionGroup, functionKind)
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	core := kubernetes.NewCore(params)
+	namespace = core.NamespaceOrDefault(namespace)
+	existing, err := core.ResourcesGet(params.Context, &functionGVK, namespace, name)
+	switch {
+	case err == nil:
+		if _, ok, _ := unstructured.NestedMap(existing.Object, "spec", "source", "gitRepository"); ok {
+			return api.NewToolCallResult("", fmt.Errorf("function %s/%s uses a git repository source and cannot be updated with inline source code", namespace, name)), nil
+		}
+	case !apierrors.IsNotFound(err):
+		mcplog.HandleK8sError(params.Context, err, "function access")
+		return api.NewToolCallResult("", fmt.Errorf("failed to get Function: %w", err)), nil
+	}
+
+	inline := map[string]any{"source": source}
+	if dependencies != "" {
+		inline["dependencies"] = dependencies
+	}
+	function := &unstructured.Unstructured{Object: map[string]any{
+		"spec": map[string]any{
+			"runtime": runtime,
+			"source":  map[string]any{"inline": inline},
+		},
+	}}
+	function.SetGroupVersionKind(functionGVK)
+	function.SetNamespace(namespace)
+	function.SetName(name)
+
+	manifest, err := output.MarshalYaml(function)
+	if err != nil {
+		return api.NewToolCallResult("", fmt.Errorf("failed to marshal Function: %w", err)), nil
+	}
+	resources, err := core.ResourcesCreateOrUpdate(params.Context, manifest)
+	if err != nil {
+		mcplog.HandleK8sError(params.Context, err, "function create or update")
+		return api.NewToolCallResult("", fmt.Errorf("failed to create or update Function: %w", err)), nil
+	}
+	marshalled, err := output.MarshalYaml(resources)
+	if err != nil {
+		return api.NewToolCallResult("", fmt.Errorf("failed to marshal Function: %w", err)), nil
+	}
+	return api.NewToolCallResult("# The following Function (YAML) has been created or updated successfully, "+
+		"check its conditions with serverless_function_get until it is Running\n"+marshalled, nil), nil
+}
+
+// getFunction gets the Function selected by the optional namespace and the required name arguments.
+func getFunction(params api.ToolHandlerParams) (*unstructured.Unstructured, error) {
+	args := params.GetArguments()
+	namespace, err := common.GetOptionalString(args, "namespace")
+	if err != nil {
+		return nil, err
+	}
+	name, err := common.GetRequiredString(args, "name")
+	if err != nil {
+		return nil, err
+	}
+
+	functionGVK, err := common.ResolveGroupVersionKind(params.DiscoveryClient(), functionGroup, functionKind)
+	if err != nil {
+		return nil, err
+	}
+	ret, err := kubernetes.NewCore(params).ResourcesGet(params.Context, &functionGVK, namespace, name)
+	if err != nil {
+		mcplog.HandleK8sError(params.Context, err, "function access")
+		return nil, fmt.Errorf("failed to get Function: %w", err)
+	}
+	return ret, nil
+}
+
+func isFunctionRuntime(runtime string) bool {
+	for _, supported := range functionRuntimes {
+		if supported == runtime {
+			return true
+		}
+	}
+	return false
+}
+
+// functionSourceType returns inline or git, depending on the Function source.
+func functionSourceType(function *unstructured.Unstructured) string {
+	if _, ok, _ := unstructured.NestedMap(function.Object, "spec", "source", "gitRepository"); ok {
+		return "git"
+	}
+	if _, ok, _ := unstructured.NestedMap(function.Object, "spec", "source", "inline"); ok {
+		return "inline"
+	}
+	return "-"
+}
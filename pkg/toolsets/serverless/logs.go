@@ -0,0 +1,165 @@
+package serverless
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/containers/kubernetes-mcp-server/pkg/api"
+	"github.com/containers/kubernetes-mcp-server/pkg/kubernetes"
+	"github.com/containers/kubernetes-mcp-server/pkg/mcplog"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/mfaizanse/ext-kyma-mcp/pkg/toolsets/common"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	functionNameLabel = "serverless.kyma-project.io/function-name"
+
+	logsIncludeRuntime = "runtime"
+	logsIncludeBuild   = "build"
+	logsIncludeAll     = "all"
+
+	// maxBuildPods is the number of the most recent build job pods whose logs are fetched.
+	maxBuildPods = 2
+)
+
+func initFunctionLogs() []api.ServerTool {
+	properties := functionProperties()
+	properties["include"] = &jsonschema.Schema{
+		Type:        "string",
+		Description: "Which pods to fetch the logs from: runtime (the current Function pods), build (the most recent build jobs) or all (defaults to all)",
+		Enum:        []any{logsIncludeRuntime, logsIncludeBuild, logsIncludeAll},
+	}
+	properties["tail"] = &jsonschema.Schema{
+		Type:        "integer",
+		Description: "Number of lines to retrieve from the end of the logs of each container (defaults to 100)",
+		Minimum:     ptr.To(float64(0)),
+	}
+	properties["previous"] = &jsonschema.Schema{
+		Type:        "boolean",
+		Description: "Return the logs of the previous terminated runtime containers, e.g. after a crash (defaults to false)",
+	}
+	return []api.ServerTool{
+		{
+			Tool: api.Tool{
+				Name:        "serverless_function_logs",
+				Description: "Get the logs of a Kyma Serverless Function's current pods and of its most recent build jobs",
+				InputSchema: &jsonschema.Schema{
+					Type:       "object",
+					Properties: properties,
+					Required:   []string{"name"},
+				},
+				Annotations: api.ToolAnnotations{
+					Title:           "Serverless: Function Logs",
+					ReadOnlyHint:    ptr.To(true),
+					DestructiveHint: ptr.To(false),
+					OpenWorldHint:   ptr.To(true),
+				},
+			},
+			Handler: functionLogs,
+		},
+	}
+}
+
+func functionLogs(params api.ToolHandlerParams) (*api.ToolCallResult, error) {
+	args := params.GetArguments()
+	namespace, err := common.GetOptionalString(args, "namespace")
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	name, err := common.GetRequiredString(args, "name")
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	include, err := common.GetOptionalStringDefault(args, "include", logsIncludeAll)
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	if include != logsIncludeRuntime && include != logsIncludeBuild && include != logsIncludeAll {
+		return api.NewToolCallResult("", fmt.Errorf("include must be %s, %s or %s", logsIncludeRuntime, logsIncludeBuild, logsIncludeAll)), nil
+	}
+	tail, err := common.GetOptionalInt(args, "tail", 100)
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	previous, err := common.GetOptionalBool(args, "previous", false)
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+
+	core := kubernetes.NewCore(params)
+	namespace = core.NamespaceOrDefault(namespace)
+	pods, err := params.CoreV1().Pods(namespace).List(params.Context, metav1.ListOptions{LabelSelector: functionNameLabel + "=" + name})
+	if err != nil {
+		mcplog.HandleK8sError(params.Context, err, "pods listing")
+		return api.NewToolCallResult("", fmt.Errorf("failed to list the pods of Function %s/%s: %w", namespace, name, err)), nil
+	}
+
+	var runtimePods, buildPods []v1.Pod
+	for _, pod := range pods.Items {
+		if isBuildPod(&pod) {
+			buildPods = append(buildPods, pod)
+		} else if pod.DeletionTimestamp == nil {
+			runtimePods = append(runtimePods, pod)
+		}
+	}
+	// the most recent build jobs first
+	sort.Slice(buildPods, func(i, j int) bool {
+		return buildPods[j].CreationTimestamp.Before(&buildPods[i].CreationTimestamp)
+	})
+	if len(buildPods) > maxBuildPods {
+		buildPods = buildPods[:maxBuildPods]
+	}
+
+	var sections []string
+	if include != logsIncludeBuild {
+		sections = append(sections, podsLogs(params, core, "Runtime pod", runtimePods, previous, int64(tail))...)
+		if len(runtimePods) == 0 {
+			sections = append(sections, "# No running pods found for the Function, check its BuildReady and Running conditions")
+		}
+	}
+	if include != logsIncludeRuntime {
+		sections = append(sections, podsLogs(params, core, "Build job pod", buildPods, false, int64(tail))...)
+		if len(buildPods) == 0 {
+			sections = append(sections, "# No build job pods found for the Function")
+		}
+	}
+	return api.NewToolCallResult(strings.Join(sections, "\n\n"), nil), nil
+}
+
+// isBuildPod returns true if the pod is owned by a Function build Job.
+func isBuildPod(pod *v1.Pod) bool {
+	for _, owner := range pod.OwnerReferences {
+		if owner.Kind == "Job" {
+			return true
+		}
+	}
+	return false
+}
+
+// podsLogs fetches the logs of every container of the pods, a failure to fetch the logs of a container is reported in its section.
+func podsLogs(params api.ToolHandlerParams, core *kubernetes.Core, title string, pods []v1.Pod, previous bool, tail int64) []string {
+	var ret []string
+	for _, pod := range pods {
+		containers := make([]v1.Container, 0, len(pod.Spec.InitContainers)+len(pod.Spec.Containers))
+		containers = append(containers, pod.Spec.InitContainers...)
+		containers = append(containers, pod.Spec.Containers...)
+		for _, container := range containers {
+			header := fmt.Sprintf("# %s %s, container %s (phase %s)", title, pod.Name, container.Name, pod.Status.Phase)
+			logs, err := core.PodsLog(params.Context, pod.Namespace, pod.Name, container.Name, previous, tail)
+			switch {
+			case err != nil:
+				mcplog.HandleK8sError(params.Context, err, "pod log access")
+				ret = append(ret, fmt.Sprintf("%s\nfailed to get the logs: %v", header, err))
+			case strings.TrimSpace(logs) == "":
+				ret = append(ret, header+"\nno logs")
+			default:
+				ret = append(ret, header+"\n"+strings.TrimRight(logs, "\n"))
+			}
+		}
+	}
+	return ret
+}
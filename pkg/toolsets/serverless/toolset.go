@@ -0,0 +1,32 @@
+package serverless
+
+import (
+	"slices"
+
+	"github.com/containers/kubernetes-mcp-server/pkg/api"
+	"github.com/containers/kubernetes-mcp-server/pkg/toolsets"
+)
+
+type Toolset struct{}
+
+var _ api.Toolset = (*Toolset)(nil)
+
+func (t *Toolset) GetName() string {
+	return "serverless"
+}
+
+func (t *Toolset) GetDescription() string {
+	return "Kyma Serverless tools for inspecting, deploying and troubleshooting Functions"
+}
+
+func (t *Toolset) GetTools(api.Openshift) []api.ServerTool {
+	return slices.Concat(initFunctions(), initFunctionLogs())
+}
+
+func (t *Toolset) GetPrompts() []api.ServerPrompt {
+	return nil
+}
+
+func init() {
+	toolsets.Register(&Toolset{})
+}
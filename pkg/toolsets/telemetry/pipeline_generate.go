@@ -0,0 +1,247 @@
+package telemetry
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/containers/kubernetes-mcp-server/pkg/api"
+	"github.com/containers/kubernetes-mcp-server/pkg/output"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/mfaizanse/ext-kyma-mcp/pkg/toolsets/common"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	authenticationNone   = "none"
+	authenticationBasic  = "basic"
+	authenticationHeader = "header"
+)
+
+func initPipelineGenerate() []api.ServerTool {
+	return []api.ServerTool{
+		{
+			Tool: api.Tool{
+				Name: "telemetry_pipeline_generate",
+				Description: "Generate the manifest of a Kyma Telemetry LogPipeline, TracePipeline or MetricPipeline sending the data to an OTLP backend. " +
+					"Credentials are always referenced from a Secret. The manifest is checked for misconfigurations but not applied, apply it with resources_create_or_update.",
+				InputSchema: &jsonschema.Schema{
+					Type: "object",
+					Properties: map[string]*jsonschema.Schema{
+						"kind": {
+							Type:        "string",
+							Description: "Kind of the pipeline",
+							Enum:        []any{logPipelineKind, tracePipelineKind, metricPipelineKind},
+						},
+						"name": {
+							Type:        "string",
+							Description: "Name of the pipeline",
+						},
+						"endpoint": {
+							Type:        "string",
+							Description: "URL of the OTLP backend, e.g. https://otlp.example.com:4317 (required unless endpointSecretKey is set)",
+						},
+						"endpointSecretKey": {
+							Type:        "string",
+							Description: "Key of the Secret holding the OTLP backend URL, instead of endpoint (optional, requires secretName and secretNamespace)",
+						},
+						"protocol": {
+							Type:        "string",
+							Description: "OTLP protocol (defaults to grpc)",
+							Enum:        []any{protocolGRPC, protocolHTTP},
+						},
+						"authentication": {
+							Type:        "string",
+							Description: "Authentication against the backend: none, basic (user and password keys of the Secret) or header (a token key of the Secret sent in a header) (defaults to none)",
+							Enum:        []any{authenticationNone, authenticationBasic, authenticationHeader},
+						},
+						"secretName": {
+							Type:        "string",
+							Description: "Name of the Secret holding the credentials (or the endpoint)",
+						},
+						"secretNamespace": {
+							Type:        "string",
+							Description: "Namespace of the Secret holding the credentials (or the endpoint)",
+						},
+						"userKey": {
+							Type:        "string",
+							Description: "Key of the Secret holding the basic authentication user (defaults to user)",
+						},
+						"passwordKey": {
+							Type:        "string",
+							Description: "Key of the Secret holding the basic authentication password (defaults to password)",
+						},
+						"headerName": {
+							Type:        "string",
+							Description: "Name of the header for the header authentication (defaults to Authorization)",
+						},
+						"headerPrefix": {
+							Type:        "string",
+							Description: "Prefix of the header value for the header authentication, e.g. Bearer or Api-Token (optional)",
+						},
+						"headerKey": {
+							Type:        "string",
+							Description: "Key of the Secret holding the header value for the header authentication (defaults to token)",
+						},
+						"namespaces": {
+							Type:        "array",
+							Description: "Namespaces to collect the application logs from (optional, LogPipeline only, defaults to all namespaces except the system ones)",
+							Items:       &jsonschema.Schema{Type: "string"},
+						},
+					},
+					Required: []string{"kind", "name"},
+				},
+				Annotations: api.ToolAnnotations{
+					Title:           "Telemetry: Pipeline Generate",
+					ReadOnlyHint:    ptr.To(true),
+					DestructiveHint: ptr.To(false),
+					OpenWorldHint:   ptr.To(true),
+				},
+			},
+			Handler: pipelineGenerate,
+		},
+	}
+}
+
+func pipelineGenerate(params api.ToolHandlerParams) (*api.ToolCallResult, error) {
+	args := params.GetArguments()
+	kind, err := common.GetRequiredString(args, "kind")
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	name, err := common.GetRequiredString(args, "name")
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	gvk, err := resolvePipelineGVK(params, kind)
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	otlp, err := otlpOutput(args)
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	namespaces, err := common.GetOptionalStringArray(args, "namespaces", nil)
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	if len(namespaces) > 0 && kind != logPipelineKind {
+		return api.NewToolCallResult("", fmt.Errorf("namespaces is only supported for %ss", logPipelineKind)), nil
+	}
+
+	spec := map[string]any{"output": map[string]any{"otlp": otlp}}
+	if len(namespaces) > 0 {
+		include := make([]any, len(namespaces))
+		for i, namespace := range namespaces {
+			include[i] = namespace
+		}
+		spec["input"] = map[string]any{
+			"application": map[string]any{"namespaces": map[string]any{"include": include}},
+		}
+	}
+	pipeline := &unstructured.Unstructured{Object: map[string]any{"spec": spec}}
+	pipeline.SetGroupVersionKind(gvk)
+	pipeline.SetName(name)
+
+	manifest, err := output.MarshalYaml(pipeline)
+	if err != nil {
+		return api.NewToolCallResult("", fmt.Errorf("failed to marshal %s: %w", kind, err)), nil
+	}
+	ret := "# The following " + kind + " (YAML) has been generated, it was not applied\n" + manifest
+	if findings := analyzePipeline(pipeline).findings; len(findings) > 0 {
+		ret += "\n# Warnings\n- " + strings.Join(findings, "\n- ")
+	}
+	return api.NewToolCallResult(ret, nil), nil
+}
+
+// otlpOutput builds the otlp output of the pipeline spec from the tool arguments.
+func otlpOutput(args map[string]any) (map[string]any, error) {
+	endpoint, err := common.GetOptionalString(args, "endpoint")
+	if err != nil {
+		return nil, err
+	}
+	endpointSecretKey, err := common.GetOptionalString(args, "endpointSecretKey")
+	if err != nil {
+		return nil, err
+	}
+	protocol, err := common.GetOptionalStringDefault(args, "protocol", protocolGRPC)
+	if err != nil {
+		return nil, err
+	}
+	if protocol != protocolGRPC && protocol != protocolHTTP {
+		return nil, fmt.Errorf("protocol must be %s or %s", protocolGRPC, protocolHTTP)
+	}
+	authentication, err := common.GetOptionalStringDefault(args, "authentication", authenticationNone)
+	if err != nil {
+		return nil, err
+	}
+	secretName, err := common.GetOptionalString(args, "secretName")
+	if err != nil {
+		return nil, err
+	}
+	secretNamespace, err := common.GetOptionalString(args, "secretNamespace")
+	if err != nil {
+		return nil, err
+	}
+	secretRequired := endpointSecretKey != "" || authentication != authenticationNone
+	if secretRequired && (secretName == "" || secretNamespace == "") {
+		return nil, fmt.Errorf("secretName and secretNamespace are required for the %s authentication or an endpointSecretKey", authentication)
+	}
+	valueFrom := func(key string) map[string]any {
+		return map[string]any{
+			"valueFrom": map[string]any{
+				"secretKeyRef": map[string]any{"name": secretName, "namespace": secretNamespace, "key": key},
+			},
+		}
+	}
+
+	otlp := map[string]any{"protocol": protocol}
+	switch {
+	case endpoint != "" && endpointSecretKey != "":
+		return nil, fmt.Errorf("set either endpoint or endpointSecretKey")
+	case endpoint != "":
+		otlp["endpoint"] = map[string]any{"value": endpoint}
+	case endpointSecretKey != "":
+		otlp["endpoint"] = valueFrom(endpointSecretKey)
+	default:
+		return nil, fmt.Errorf("endpoint or endpointSecretKey is required")
+	}
+
+	switch authentication {
+	case authenticationNone:
+	case authenticationBasic:
+		userKey, err := common.GetOptionalStringDefault(args, "userKey", "user")
+		if err != nil {
+			return nil, err
+		}
+		passwordKey, err := common.GetOptionalStringDefault(args, "passwordKey", "password")
+		if err != nil {
+			return nil, err
+		}
+		otlp["authentication"] = map[string]any{
+			"basic": map[string]any{"user": valueFrom(userKey), "password": valueFrom(passwordKey)},
+		}
+	case authenticationHeader:
+		headerName, err := common.GetOptionalStringDefault(args, "headerName", "Authorization")
+		if err != nil {
+			return nil, err
+		}
+		headerPrefix, err := common.GetOptionalString(args, "headerPrefix")
+		if err != nil {
+			return nil, err
+		}
+		headerKey, err := common.GetOptionalStringDefault(args, "headerKey", "token")
+		if err != nil {
+			return nil, err
+		}
+		header := valueFrom(headerKey)
+		header["name"] = headerName
+		if headerPrefix != "" {
+			header["prefix"] = headerPrefix
+		}
+		otlp["headers"] = []any{header}
+	default:
+		return nil, fmt.Errorf("authentication must be %s, %s or %s", authenticationNone, authenticationBasic, authenticationHeader)
+	}
+	return otlp, nil
+}
@@ -0,0 +1,412 @@
+package telemetry
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/containers/kubernetes-mcp-server/pkg/api"
+	"github.com/containers/kubernetes-mcp-server/pkg/kubernetes"
+	"github.com/containers/kubernetes-mcp-server/pkg/mcplog"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/mfaizanse/ext-kyma-mcp/pkg/toolsets/common"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	telemetryGroup = "telemetry.kyma-project.io"
+
+	logPipelineKind    = "LogPipeline"
+	tracePipelineKind  = "TracePipeline"
+	metricPipelineKind = "MetricPipeline"
+
+	conditionAgentHealthy           = "AgentHealthy"
+	conditionGatewayHealthy         = "GatewayHealthy"
+	conditionConfigurationGenerated = "ConfigurationGenerated"
+	conditionTelemetryFlowHealthy   = "TelemetryFlowHealthy"
+
+	protocolGRPC = "grpc"
+	protocolHTTP = "http"
+)
+
+var pipelineKinds = []string{logPipelineKind, tracePipelineKind, metricPipelineKind}
+
+// reasonHints explain the condition reasons reported by the Telemetry module.
+var reasonHints = map[string]string{
+	"MaxPipelinesExceeded":        "the maximum number of pipelines of this kind is exceeded, delete unused pipelines",
+	"ReferencedSecretMissing":     "a referenced Secret or Secret key is missing",
+	"TLSCertificateInvalid":       "the TLS certificate or key is invalid",
+	"TLSCertificateExpired":       "the TLS certificate is expired",
+	"TLSCertificateAboutToExpire": "the TLS certificate is about to expire",
+	"EndpointInvalid":             "the output endpoint is invalid",
+	"GatewayThrottling":           "the gateway is throttling, the backend or the gateway cannot cope with the data volume",
+	"BufferFillingUp":             "the buffer is filling up, the backend is slow or unreachable",
+	"SomeDataDropped":             "some data is dropped, the backend is slow, unreachable or rejects data",
+	"AllDataDropped":              "all data is dropped, the backend is unreachable or rejects the data (check the endpoint and the credentials)",
+	"NoLogsDelivered":             "no logs are delivered, the backend is unreachable or rejects the logs",
+	"DeploymentNotReady":          "the gateway Deployment is not ready",
+	"DaemonSetNotReady":           "the agent DaemonSet is not ready",
+}
+
+func initPipelines() []api.ServerTool {
+	return []api.ServerTool{
+		{
+			Tool: api.Tool{
+				Name: "telemetry_pipelines_list",
+				Description: "List the Kyma Telemetry LogPipelines, TracePipelines and MetricPipelines with their output and their " +
+					"AgentHealthy, GatewayHealthy, ConfigurationGenerated and TelemetryFlowHealthy conditions, and flag common misconfigurations",
+				InputSchema: &jsonschema.Schema{
+					Type: "object",
+					Properties: map[string]*jsonschema.Schema{
+						"kind": {
+							Type:        "string",
+							Description: "Kind of the pipelines to list (optional, lists all pipeline kinds if not provided)",
+							Enum:        []any{logPipelineKind, tracePipelineKind, metricPipelineKind},
+						},
+					},
+				},
+				Annotations: api.ToolAnnotations{
+					Title:           "Telemetry: Pipelines List",
+					ReadOnlyHint:    ptr.To(true),
+					DestructiveHint: ptr.To(false),
+					OpenWorldHint:   ptr.To(true),
+				},
+			},
+			Handler: pipelinesList,
+		},
+		{
+			Tool: api.Tool{
+				Name: "telemetry_pipeline_inspect",
+				Description: "Inspect a Kyma Telemetry pipeline: its output, its conditions, the keys (never the values) of the Secrets it references, " +
+					"and the misconfigurations found such as missing output Secret refs, protocol and port mismatches or exceeded pipeline limits",
+				InputSchema: &jsonschema.Schema{
+					Type: "object",
+					Properties: map[string]*jsonschema.Schema{
+						"kind": {
+							Type:        "string",
+							Description: "Kind of the pipeline",
+							Enum:        []any{logPipelineKind, tracePipelineKind, metricPipelineKind},
+						},
+						"name": {
+							Type:        "string",
+							Description: "Name of the pipeline (pipelines are cluster-scoped)",
+						},
+					},
+					Required: []string{"kind", "name"},
+				},
+				Annotations: api.ToolAnnotations{
+					Title:           "Telemetry: Pipeline Inspect",
+					ReadOnlyHint:    ptr.To(true),
+					DestructiveHint: ptr.To(false),
+					OpenWorldHint:   ptr.To(true),
+				},
+			},
+			Handler: pipelineInspect,
+		},
+	}
+}
+
+// resolvePipelineGVK validates the pipeline kind and resolves its served version.
+func resolvePipelineGVK(params api.ToolHandlerParams, kind string) (schema.GroupVersionKind, error) {
+	if !slices.Contains(pipelineKinds, kind) {
+		return schema.GroupVersionKind{}, fmt.Errorf("kind must be one of %s", strings.Join(pipelineKinds, ", "))
+	}
+	return common.ResolveGroupVersionKind(params.DiscoveryClient(), telemetryGroup, kind)
+}
+
+func pipelinesList(params api.ToolHandlerParams) (*api.ToolCallResult, error) {
+	kind, err := common.GetOptionalString(params.GetArguments(), "kind")
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	kinds := pipelineKinds
+	if kind != "" {
+		kinds = []string{kind}
+	}
+
+	buf := new(bytes.Buffer)
+	w := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "KIND\tNAME\tOUTPUT\tCONFIGURATION\tAGENT\tGATEWAY\tFLOW")
+	var findings, unavailable []string
+	count := 0
+	for _, kind := range kinds {
+		gvk, err := resolvePipelineGVK(params, kind)
+		if err != nil {
+			unavailable = append(unavailable, fmt.Sprintf("- %v", err))
+			continue
+		}
+		pipelines, err := common.ListResources(params, gvk, "")
+		if err != nil {
+			unavailable = append(unavailable, fmt.Sprintf("- failed to list %ss: %v", kind, err))
+			continue
+		}
+		sort.Slice(pipelines, func(i, j int) bool { return pipelines[i].GetName() < pipelines[j].GetName() })
+		for i := range pipelines {
+			pipeline := &pipelines[i]
+			count++
+			conditions := common.GetConditions(pipeline)
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				kind, pipeline.GetName(), pipelineOutput(pipeline),
+				conditions.Status(conditionConfigurationGenerated), conditions.Status(conditionAgentHealthy),
+				conditions.Status(conditionGatewayHealthy), conditions.Status(conditionTelemetryFlowHealthy))
+			for _, finding := range analyzePipeline(pipeline).findings {
+				findings = append(findings, fmt.Sprintf("- %s %s: %s", kind, pipeline.GetName(), finding))
+			}
+		}
+	}
+	_ = w.Flush()
+
+	var sections []string
+	if count == 0 {
+		sections = append(sections, "# No pipelines found")
+	} else {
+		sections = append(sections, strings.TrimSpace(buf.String()))
+	}
+	if len(findings) > 0 {
+		sections = append(sections, "# Misconfigurations and problems (inspect them with telemetry_pipeline_inspect)\n"+strings.Join(findings, "\n"))
+	}
+	if len(unavailable) > 0 {
+		sections = append(sections, "# Unavailable pipeline kinds\n"+strings.Join(unavailable, "\n"))
+	}
+	return api.NewToolCallResult(strings.Join(sections, "\n\n"), nil), nil
+}
+
+func pipelineInspect(params api.ToolHandlerParams) (*api.ToolCallResult, error) {
+	args := params.GetArguments()
+	kind, err := common.GetRequiredString(args, "kind")
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	name, err := common.GetRequiredString(args, "name")
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+	gvk, err := resolvePipelineGVK(params, kind)
+	if err != nil {
+		return api.NewToolCallResult("", err), nil
+	}
+
+	pipeline, err := kubernetes.NewCore(params).ResourcesGet(params.Context, &gvk, "", name)
+	if err != nil {
+		mcplog.HandleK8sError(params.Context, err, "pipeline access")
+		return api.NewToolCallResult("", fmt.Errorf("failed to get %s: %w", kind, err)), nil
+	}
+
+	analysis := analyzePipeline(pipeline)
+	analyzeSecretKeyRefs(params, pipeline, analysis)
+	lines := []string{
+		fmt.Sprintf("# %s %s (%s)", kind, name, gvk.GroupVersion().String()),
+		"- output: " + pipelineOutput(pipeline),
+	}
+	if endpoint, ok, _ := unstructured.NestedString(pipeline.Object, "spec", "output", "otlp", "endpoint", "value"); ok {
+		lines = append(lines, "- endpoint: "+endpoint)
+	}
+	for _, condition := range common.GetConditions(pipeline) {
+		lines = append(lines, fmt.Sprintf("- condition %s=%s (%s): %s",
+			condition.Type, condition.Status, common.ValueOrDash(condition.Reason), common.ValueOrDash(condition.Message)))
+	}
+
+	lines = append(lines, "", "## Referenced Secrets (keys only)")
+	if len(analysis.secrets) == 0 {
+		lines = append(lines, "- none")
+	}
+	lines = append(lines, analysis.secrets...)
+
+	lines = append(lines, "", "## Misconfigurations and problems")
+	if len(analysis.findings) == 0 {
+		lines = append(lines, "- none found")
+	}
+	for _, finding := range analysis.findings {
+		lines = append(lines, "- "+finding)
+	}
+	return api.NewToolCallResult(strings.Join(lines, "\n"), nil), nil
+}
+
+// pipelineOutput returns the output type of the pipeline (otlp, http or custom) and its protocol for otlp.
+func pipelineOutput(pipeline *unstructured.Unstructured) string {
+	output, _, _ := unstructured.NestedMap(pipeline.Object, "spec", "output")
+	if otlp, ok := output["otlp"].(map[string]any); ok {
+		protocol, _ := otlp["protocol"].(string)
+		if protocol == "" {
+			protocol = protocolGRPC
+		}
+		return "otlp/" + protocol
+	}
+	for _, outputType := range []string{"http", "custom"} {
+		if _, ok := output[outputType]; ok {
+			return outputType
+		}
+	}
+	return "-"
+}
+
+type pipelineAnalysis struct {
+	findings []string
+	secrets  []string
+}
+
+// analyzePipeline flags the common misconfigurations of the spec and the unhealthy conditions of a pipeline.
+// The referenced Secrets are not read, see analyzeSecretKeyRefs.
+func analyzePipeline(pipeline *unstructured.Unstructured) *pipelineAnalysis {
+	analysis := &pipelineAnalysis{}
+	spec, _, _ := unstructured.NestedMap(pipeline.Object, "spec")
+	output, _ := spec["output"].(map[string]any)
+
+	switch pipelineOutput(pipeline) {
+	case "-":
+		analysis.findings = append(analysis.findings, "no output is configured, set spec.output.otlp")
+	case "custom":
+		analysis.findings = append(analysis.findings, "the custom output runs in unsupported mode, prefer an otlp or http output")
+	}
+	if otlp, ok := output["otlp"].(map[string]any); ok {
+		analysis.findings = append(analysis.findings, analyzeOTLPOutput(otlp)...)
+	}
+	if unsupported, _, _ := unstructured.NestedBool(pipeline.Object, "status", "unsupportedMode"); unsupported {
+		analysis.findings = append(analysis.findings, "the pipeline runs in unsupported mode, its configuration is not supported by the Telemetry module")
+	}
+
+	for _, ref := range pipelineSecretKeyRefs(pipeline) {
+		if ref.name == "" || ref.namespace == "" || ref.key == "" {
+			analysis.findings = append(analysis.findings, fmt.Sprintf("%s.secretKeyRef must set name, namespace and key", ref.path))
+		}
+	}
+
+	for _, condition := range common.GetConditions(pipeline) {
+		if condition.Status == string(metav1.ConditionTrue) {
+			continue
+		}
+		finding := fmt.Sprintf("condition %s is %s (%s)", condition.Type, condition.Status, common.ValueOrDash(condition.Reason))
+		if hint, ok := reasonHints[condition.Reason]; ok {
+			finding += ": " + hint
+		}
+		if condition.Message != "" {
+			finding += " - " + condition.Message
+		}
+		analysis.findings = append(analysis.findings, finding)
+	}
+	return analysis
+}
+
+// analyzeSecretKeyRefs reads the Secrets referenced by the pipeline, lists their keys and flags the missing Secrets and keys.
+func analyzeSecretKeyRefs(params api.ToolHandlerParams, pipeline *unstructured.Unstructured, analysis *pipelineAnalysis) {
+	keys := make(map[string][]string)
+	for _, ref := range pipelineSecretKeyRefs(pipeline) {
+		if ref.name == "" || ref.namespace == "" || ref.key == "" {
+			continue
+		}
+		reference := ref.namespace + "/" + ref.name
+		secretKeys, read := keys[reference]
+		if !read {
+			secret, err := params.CoreV1().Secrets(ref.namespace).Get(params.Context, ref.name, metav1.GetOptions{})
+			switch {
+			case apierrors.IsNotFound(err):
+				analysis.findings = append(analysis.findings, fmt.Sprintf("the Secret %s referenced by %s does not exist", reference, ref.path))
+				secretKeys = nil
+			case err != nil:
+				mcplog.HandleK8sError(params.Context, err, "secret access")
+				analysis.secrets = append(analysis.secrets, fmt.Sprintf("- %s: keys unavailable: %v", reference, err))
+				// unknown keys are not reported as missing
+				secretKeys = []string{ref.key}
+			default:
+				secretKeys = make([]string, 0, len(secret.Data))
+				for key := range secret.Data {
+					secretKeys = append(secretKeys, key)
+				}
+				sort.Strings(secretKeys)
+				analysis.secrets = append(analysis.secrets, fmt.Sprintf("- %s: keys %s", reference, common.ValueOrDash(strings.Join(secretKeys, ", "))))
+			}
+			keys[reference] = secretKeys
+		}
+		if secretKeys != nil && !slices.Contains(secretKeys, ref.key) {
+			analysis.findings = append(analysis.findings, fmt.Sprintf("the Secret %s has no key %s referenced by %s", reference, ref.key, ref.path))
+		}
+	}
+}
+
+// pipelineSecretKeyRefs returns the secretKeyRefs of the pipeline spec, sorted by path.
+func pipelineSecretKeyRefs(pipeline *unstructured.Unstructured) []secretKeyRef {
+	spec, _, _ := unstructured.NestedMap(pipeline.Object, "spec")
+	refs := collectSecretKeyRefs(spec, "spec")
+	sort.Slice(refs, func(i, j int) bool { return refs[i].path < refs[j].path })
+	return refs
+}
+
+// analyzeOTLPOutput flags a missing endpoint, missing credentials and protocol and port mismatches of an otlp output.
+func analyzeOTLPOutput(otlp map[string]any) []string {
+	var ret []string
+	endpoint, _ := otlp["endpoint"].(map[string]any)
+	value, _ := endpoint["value"].(string)
+	_, hasValueFrom := endpoint["valueFrom"]
+	if value == "" && !hasValueFrom {
+		ret = append(ret, "the otlp output has no endpoint, set spec.output.otlp.endpoint.value or valueFrom.secretKeyRef")
+	}
+	if value != "" {
+		protocol, _ := otlp["protocol"].(string)
+		if protocol == "" {
+			protocol = protocolGRPC
+		}
+		if parsed, err := url.Parse(value); err != nil || parsed.Host == "" {
+			ret = append(ret, fmt.Sprintf("the otlp endpoint %s is not a valid URL with scheme and host", value))
+		} else {
+			switch {
+			case protocol == protocolGRPC && parsed.Port() == "4318":
+				ret = append(ret, "the otlp endpoint uses port 4318 (OTLP/HTTP) with the grpc protocol, set protocol: http or use port 4317")
+			case protocol == protocolHTTP && parsed.Port() == "4317":
+				ret = append(ret, "the otlp endpoint uses port 4317 (OTLP/gRPC) with the http protocol, set protocol: grpc or use port 4318")
+			}
+		}
+	}
+	if basic, ok, _ := unstructured.NestedMap(otlp, "authentication", "basic"); ok {
+		for _, field := range []string{"user", "password"} {
+			fieldMap, _ := basic[field].(map[string]any)
+			fieldValue, _ := fieldMap["value"].(string)
+			if _, hasValueFrom := fieldMap["valueFrom"]; fieldValue == "" && !hasValueFrom {
+				ret = append(ret, fmt.Sprintf("the basic authentication has no %s, set its value or valueFrom.secretKeyRef", field))
+			}
+		}
+	}
+	if insecure, _, _ := unstructured.NestedBool(otlp, "tls", "insecure"); insecure {
+		ret = append(ret, "TLS is disabled (tls.insecure), the data is sent unencrypted")
+	}
+	return ret
+}
+
+type secretKeyRef struct {
+	path      string
+	name      string
+	namespace string
+	key       string
+}
+
+// collectSecretKeyRefs walks the pipeline spec for the valueFrom.secretKeyRef references.
+func collectSecretKeyRefs(value any, path string) []secretKeyRef {
+	var ret []secretKeyRef
+	switch typed := value.(type) {
+	case map[string]any:
+		if ref, ok := typed["secretKeyRef"].(map[string]any); ok {
+			r := secretKeyRef{path: path}
+			r.name, _ = ref["name"].(string)
+			r.namespace, _ = ref["namespace"].(string)
+			r.key, _ = ref["key"].(string)
+			ret = append(ret, r)
+		}
+		for key, item := range typed {
+			if key != "secretKeyRef" {
+				ret = append(ret, collectSecretKeyRefs(item, path+"."+key)...)
+			}
+		}
+	case []any:
+		for i, item := range typed {
+			ret = append(ret, collectSecretKeyRefs(item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	}
+	return ret
+}